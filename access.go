@@ -0,0 +1,78 @@
+package posixperm
+
+import "fmt"
+
+// Access is a set of requested or granted access rights. Its bits are laid out like a single class of
+// octal permission digit, so Access(5) is read and execute.
+type Access uint8
+
+const (
+	AccessExecute Access = 1 << iota
+	AccessWrite
+	AccessRead
+
+	AccessNone Access = 0
+	AccessAll         = AccessRead | AccessWrite | AccessExecute
+)
+
+// ParseAccess parses s as a set of access rights, written as any combination of the letters `r`, `w`,
+// and `x` in any order (eg `rw` or `xr`), optionally with `-` placeholders as in `r-x`. A lone `-` or
+// an empty string means no access. Repeated or unknown letters are an error.
+func ParseAccess(s string) (a Access, err error) {
+	err = a.UnmarshalText([]byte(s))
+	return
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for this type, following the rules of ParseAccess.
+func (a *Access) UnmarshalText(b []byte) error {
+	var r Access
+	for _, c := range b {
+		var bit Access
+		switch c {
+		case 'r':
+			bit = AccessRead
+		case 'w':
+			bit = AccessWrite
+		case 'x':
+			bit = AccessExecute
+		case '-':
+			continue
+		default:
+			return fmt.Errorf("unrecognized access syntax %q", b)
+		}
+		if r&bit != 0 {
+			return fmt.Errorf("access %q repeats %q", b, c)
+		}
+		r |= bit
+	}
+	*a = r
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler for this type. It returns the String() representation.
+func (a Access) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// String returns the letters of the rights in a in `rwx` order, or `-` if a is empty.
+func (a Access) String() string {
+	b := make([]byte, 0, 3)
+	if a&AccessRead != 0 {
+		b = append(b, 'r')
+	}
+	if a&AccessWrite != 0 {
+		b = append(b, 'w')
+	}
+	if a&AccessExecute != 0 {
+		b = append(b, 'x')
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
+
+// Has reports whether a includes every right in want.
+func (a Access) Has(want Access) bool {
+	return a&want == want
+}
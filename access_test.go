@@ -0,0 +1,61 @@
+package posixperm
+
+import "testing"
+
+func TestValidAccess(t *testing.T) {
+	C := []struct {
+		s string
+		v Access
+	}{
+		{"r", AccessRead},
+		{"rw", AccessRead | AccessWrite},
+		{"rx", AccessRead | AccessExecute},
+		{"xr", AccessRead | AccessExecute},
+		{"r-x", AccessRead | AccessExecute},
+		{"rwx", AccessAll},
+		{"-", AccessNone},
+		{"", AccessNone},
+	}
+	for _, c := range C {
+		a, err := ParseAccess(c.s)
+		if err != nil {
+			t.Errorf("with %q, expected %v. got error: %v", c.s, c.v, err)
+		}
+		if a != c.v {
+			t.Errorf("with %q, expected %v. got %v", c.s, c.v, a)
+		}
+	}
+}
+
+func TestInvalidAccess(t *testing.T) {
+	C := []string{"rr", "rwxw", "R", "a", "r w"}
+	for _, c := range C {
+		a, err := ParseAccess(c)
+		if err == nil {
+			t.Errorf("got nil error for %q, parsed to %v", c, a)
+		}
+	}
+}
+
+func TestAccessString(t *testing.T) {
+	C := []struct {
+		a Access
+		s string
+	}{
+		{AccessNone, "-"},
+		{AccessRead | AccessExecute, "rx"},
+		{AccessAll, "rwx"},
+		{AccessWrite, "w"},
+	}
+	for _, c := range C {
+		if c.a.String() != c.s {
+			t.Errorf("expected %q, got %q", c.s, c.a.String())
+		}
+		if !AccessAll.Has(c.a) {
+			t.Errorf("expected AccessAll to include %v", c.a)
+		}
+	}
+	if (AccessRead | AccessExecute).Has(AccessWrite) {
+		t.Errorf("rx should not include w")
+	}
+}
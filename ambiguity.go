@@ -0,0 +1,98 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// a bare decimal number, possibly with leading zeros
+var fmtDecimal = regexp.MustCompile(`^[0-9]+$`)
+
+// fromUnix converts the traditional 12 bit unix st_mode permission encoding to a Perm.
+func fromUnix(v uint64) Perm {
+	m := fs.FileMode(v & 0o777)
+	if v&0o4000 != 0 {
+		m |= fs.ModeSetuid
+	}
+	if v&0o2000 != 0 {
+		m |= fs.ModeSetgid
+	}
+	if v&0o1000 != 0 {
+		m |= fs.ModeSticky
+	}
+	return Perm(m)
+}
+
+// octal renders the unix encoding of p in explicit octal, eg `04755`, as shown in tables, Markdown,
+// and Matcher descriptions. File type bits have no octal form and must be reported separately.
+func octal(p Perm) string {
+	return fmt.Sprintf("0%03o", unixMode(p))
+}
+
+// Interpretation is one plausible reading of a numeric permission value.
+type Interpretation struct {
+	// Base is 8 if the digits are read as octal, or 10 if read as decimal.
+	Base int
+	Perm Perm
+}
+
+// String describes the interpretation, eg `octal 0644 (-rw-r--r--)`.
+func (i Interpretation) String() string {
+	v := uint32(fs.FileMode(i.Perm).Perm()) | unixSpecial(i.Perm)
+	if i.Base == 10 {
+		return fmt.Sprintf("decimal %d (%v)", v, i.Perm)
+	}
+	return fmt.Sprintf("octal %04o (%v)", v, i.Perm)
+}
+
+// unixSpecial returns the traditional unix encoding of the special bits of p.
+func unixSpecial(p Perm) (v uint32) {
+	m := fs.FileMode(p)
+	if m&fs.ModeSetuid != 0 {
+		v |= 0o4000
+	}
+	if m&fs.ModeSetgid != 0 {
+		v |= 0o2000
+	}
+	if m&fs.ModeSticky != 0 {
+		v |= 0o1000
+	}
+	return
+}
+
+// AmbiguityReport returns the distinct ways the bare number s could be read as a permission value:
+// as octal digits, as chmod and this package read them, and as a decimal number, as JSON and many
+// configuration formats read them (eg Kubernetes' defaultMode: 420 means 0644). A result with more
+// than one Interpretation means s is ambiguous; readings that do not form a valid mode are omitted,
+// and nil is returned if s is not a bare number. The classic mistake this catches is writing 644 in a
+// field documented as decimal.
+func AmbiguityReport(s string) []Interpretation {
+	if !fmtDecimal.MatchString(s) {
+		return nil
+	}
+	var r []Interpretation
+	if o, err := strconv.ParseUint(s, 8, 32); err == nil && o <= 0o7777 {
+		r = append(r, Interpretation{Base: 8, Perm: fromUnix(o)})
+	}
+	if d, err := strconv.ParseUint(s, 10, 32); err == nil && d <= 0o7777 {
+		if len(r) == 0 || r[0].Perm != fromUnix(d) {
+			r = append(r, Interpretation{Base: 10, Perm: fromUnix(d)})
+		}
+	}
+	return r
+}
+
+func ambiguityError(b []byte) error {
+	r := AmbiguityReport(string(b))
+	if len(r) < 2 {
+		return nil
+	}
+	desc := make([]string, len(r))
+	for i := range r {
+		desc[i] = r[i].String()
+	}
+	return fmt.Errorf("permission value %q is ambiguous: it may be read as %s", b, strings.Join(desc, " or "))
+}
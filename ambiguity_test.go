@@ -0,0 +1,65 @@
+package posixperm
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func TestAmbiguityReport(t *testing.T) {
+	C := []struct {
+		s string
+		v []Interpretation
+	}{
+		{"644", []Interpretation{{8, 0o644}, {10, Perm(fs.ModeSticky | 0o204)}}},
+		{"0644", []Interpretation{{8, 0o644}, {10, Perm(fs.ModeSticky | 0o204)}}},
+		{"420", []Interpretation{{8, 0o420}, {10, 0o644}}},
+		{"493", []Interpretation{{10, 0o755}}},
+		{"7", []Interpretation{{8, 0o007}}},
+		{"4755", []Interpretation{{8, Perm(fs.ModeSetuid | 0o755)}}},
+		{"0o644", nil},
+		{"rwx", nil},
+	}
+	for _, c := range C {
+		r := AmbiguityReport(c.s)
+		if !reflect.DeepEqual(r, c.v) {
+			t.Errorf("with %q, expected %v. got %v", c.s, c.v, r)
+		}
+	}
+}
+
+func TestInterpretationString(t *testing.T) {
+	r := AmbiguityReport("420")
+	if len(r) != 2 {
+		t.Fatalf("expected 2 interpretations of 420, got %v", r)
+	}
+	if s := r[0].String(); s != "octal 0420 (-r---w----)" {
+		t.Errorf("unexpected octal description %q", s)
+	}
+	if s := r[1].String(); s != "decimal 420 (-rw-r--r--)" {
+		t.Errorf("unexpected decimal description %q", s)
+	}
+}
+
+func TestRejectAmbiguous(t *testing.T) {
+	C := []struct {
+		s  string
+		ok bool
+	}{
+		{"644", false},
+		{"0644", false},
+		{"0755", false},
+		{"0o644", true},
+		{"a=rx", true},
+		{"7", true},
+	}
+	for _, c := range C {
+		_, err := FromString(c.s, WithRejectAmbiguous(), WithLenient())
+		if c.ok && err != nil {
+			t.Errorf("with %q, got error: %v", c.s, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %q, got nil error", c.s)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package posixperm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ApplyResult records the outcome of applying a mode expression to a single path.
+type ApplyResult struct {
+	Path   string
+	Before Perm
+	After  Perm
+	// Changed is set if After differs from Before, so the mode was changed (or with DryRun, would
+	// have been).
+	Changed bool
+	// DryRun is set if the result comes from a dry run (see DryRun and CopyDryRun), in which case
+	// no mode was changed.
+	DryRun bool
+	// Time is when the mode was changed, or zero if it was not.
+	Time time.Time
+	// Err holds any error encountered reading or changing the path's mode.
+	Err error
+	// Links is the number of hard links to a file that has more than one, as a warning that changing
+	// its mode also changes it for the other paths, which may be outside the tree.
+	Links int
+	// LinkedTo is set if the path is a hard link to a file already visited at that path, in which
+	// case the mode was changed there, and Before and After are the same.
+	LinkedTo string
+}
+
+// ApplyOption configures ApplyExpr.
+type ApplyOption func(*applyOptions)
+
+type applyOptions struct {
+	skip      func(path string, d fs.DirEntry) bool
+	dryRun    bool
+	journal   io.Writer
+	logger    *slog.Logger
+	progress  *progressTracker
+	errs      treeErrors
+	confine   bool
+	sensitive Matcher
+	fsTypes   fsTypes
+	xdev      *xdev
+	throttle  throttle
+
+	noPreserveRoot       bool
+	maxEntries, maxDepth int
+}
+
+// JournalEntry records a single mode change for audit trails and rollback. Entries are written as
+// newline delimited JSON by WithJournal.
+type JournalEntry struct {
+	Time   time.Time `json:"time"`
+	Path   string    `json:"path"`
+	Before Perm      `json:"before"`
+	After  Perm      `json:"after"`
+	// Rule is the expression that caused the change.
+	Rule string `json:"rule"`
+	// DryRun is set if the change was only planned.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// WithJournal makes ApplyExpr write a JournalEntry to w, as a line of JSON, for every mode it
+// changes (or with DryRun, would change). Entries are written after each change is made; if one
+// cannot be written, ApplyExpr stops rather than make further changes that are not recorded.
+func WithJournal(w io.Writer) ApplyOption {
+	return func(o *applyOptions) { o.journal = w }
+}
+
+// WithLogger makes ApplyExpr log every mode it changes (or with DryRun, would change) to l at level
+// Info, and every path it fails to read or change at level Error. Records have the message "mode
+// changed" or "mode change failed" and the attributes path, before, after, rule, and dry_run, or path,
+// rule, and error.
+func WithLogger(l *slog.Logger) ApplyOption {
+	return func(o *applyOptions) { o.logger = l }
+}
+
+// DryRun makes ApplyExpr compute and return the changes it would make without changing any modes,
+// so that a plan can be reviewed before it is executed.
+func DryRun() ApplyOption {
+	return func(o *applyOptions) { o.dryRun = true }
+}
+
+// SkipPaths excludes every path for which match returns true from ApplyExpr. If the path is a
+// directory, its contents are excluded as well. The root may not be skipped.
+func SkipPaths(match func(path string, d fs.DirEntry) bool) ApplyOption {
+	return func(o *applyOptions) { o.skip = match }
+}
+
+// pruned reports whether a walk of root leaves out path and, if it is a directory, its contents,
+// because of OneFileSystem (tracked by x), SkipSyntheticModes, or SkipPaths.
+func (o *applyOptions) pruned(root, path string, d fs.DirEntry, x *xdev) bool {
+	if x.crosses(d) {
+		return true
+	}
+	if o.fsTypes != nil && d.IsDir() {
+		if fi, err := d.Info(); err == nil && o.fsTypes.synthetic(path, fi) {
+			return true
+		}
+	}
+	return o.skip != nil && path != root && o.skip(path, d)
+}
+
+// ApplyExpr walks the tree rooted at root and applies the chmod(1) mode operand expr to every entry,
+// exactly like `chmod -R expr root`. The expression may be numeric (eg `0644`) or symbolic (eg
+// `a+rX,go-w`), in which case it is evaluated against each entry's existing mode, `X` grants execute
+// only to directories and to files that already have an execute bit, and `=` leaves the setuid and
+// setgid bits of directories alone unless they are mentioned. Symbolic links are never followed and
+// their modes are not changed.
+//
+// A file with several hard links within the tree is changed only once, where it is first visited;
+// see ApplyResult's Links and LinkedTo.
+//
+// A result is returned for every path visited, in walk order, whether or not it changed. Errors
+// affecting a single path are recorded in its result and by default the walk continues; see
+// WithErrorPolicy. The returned error is then a *TreeError listing all of them. An invalid expression
+// is reported before anything is changed, as is a root of / (see NoPreserveRoot) or a tree exceeding
+// the limits of WithLimits.
+func ApplyExpr(root, expr string, opts ...ApplyOption) ([]ApplyResult, error) {
+	return ApplyExprContext(context.Background(), root, expr, opts...)
+}
+
+// ApplyExprContext is like ApplyExpr, but stops promptly once ctx is done, returning the results
+// for the paths visited so far along with an error wrapping ctx.Err().
+func ApplyExprContext(ctx context.Context, root, expr string, opts ...ApplyOption) ([]ApplyResult, error) {
+	f, err := chmodFunc(expr)
+	if err != nil {
+		return nil, err
+	}
+	o := applyOptions{errs: treeErrors{policy: CollectErrors}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.checkRails(ctx, root); err != nil {
+		return nil, err
+	}
+
+	chmod := os.Chmod
+	if o.confine {
+		c, r, err := confinedChmod(root)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		chmod = c
+	}
+
+	var results []ApplyResult
+	linked := make(map[[2]uint64]string)
+	walkErr := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			results = append(results, ApplyResult{Path: path, Err: err, DryRun: o.dryRun})
+			o.logFailure(path, expr, err)
+			o.progress.visit(path, 0)
+			return o.errs.add(path, err, true)
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		if o.pruned(root, path, d, o.xdev) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() && path != root {
+			if err := o.throttle.enter(ctx); err != nil {
+				return err
+			}
+		}
+		r := ApplyResult{Path: path, DryRun: o.dryRun}
+		fi, err := d.Info()
+		reading := err != nil
+		if err == nil && !fi.IsDir() {
+			if id, nlink, ok := fileLinks(fi); ok && nlink > 1 {
+				r.Links = int(nlink)
+				if first, ok := linked[id]; ok {
+					r.LinkedTo = first
+					r.Before, r.After = Perm(fi.Mode()), Perm(fi.Mode())
+					results = append(results, r)
+					o.progress.visit(path, 0)
+					return nil
+				}
+				linked[id] = path
+			}
+		}
+		if err == nil {
+			before := fi.Mode()
+			after := f(before, d.IsDir())
+			r.Before, r.After, r.Changed = Perm(before), Perm(after), after != before
+			if r.Changed {
+				r.Time = time.Now()
+				if !o.dryRun {
+					if err := o.throttle.change(ctx); err != nil {
+						return err
+					}
+					err = chmod(path, after&chmodBits)
+				}
+			}
+		}
+		if r.Changed && err == nil && o.journal != nil {
+			e := JournalEntry{Time: r.Time, Path: path, Before: r.Before, After: r.After, Rule: expr, DryRun: o.dryRun}
+			if jerr := json.NewEncoder(o.journal).Encode(e); jerr != nil {
+				results = append(results, r)
+				return fmt.Errorf("cannot write journal entry for %s: %w", path, jerr)
+			}
+		}
+		if err != nil {
+			r.Err = err
+			o.logFailure(path, expr, err)
+		} else if r.Changed && o.logger != nil {
+			o.logger.Info("mode changed", "path", path, "before", Redact(r.Before, o.sensitive).String(),
+				"after", Redact(r.After, o.sensitive).String(),
+				"rule", expr, "dry_run", o.dryRun)
+		}
+		results = append(results, r)
+		if r.Changed && r.Err == nil {
+			o.progress.visit(path, 1)
+		} else {
+			o.progress.visit(path, 0)
+		}
+		if err != nil {
+			return o.errs.add(path, err, reading)
+		}
+		return nil
+	})
+	o.progress.done()
+	return results, o.errs.result(walkErr)
+}
+
+func (o *applyOptions) logFailure(path, expr string, err error) {
+	if o.logger != nil {
+		o.logger.Error("mode change failed", "path", path, "rule", expr, "error", err)
+	}
+}
@@ -0,0 +1,319 @@
+package posixperm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makeTree creates the files and directories in modes under a new temporary directory, whose own
+// mode is set to 0700, and returns its path. Directories are named with a trailing slash.
+func makeTree(t *testing.T, modes map[string]fs.FileMode) string {
+	t.Helper()
+	root := t.TempDir()
+	for name := range modes {
+		path := filepath.Join(root, name)
+		if name[len(name)-1] == '/' {
+			if err := os.MkdirAll(path, 0o700); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, nil, 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// apply modes once everything exists, so directory modes do not get in the way of creation
+	for name, mode := range modes {
+		if err := os.Chmod(filepath.Join(root, name), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(root, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func modeOf(t *testing.T, path string) fs.FileMode {
+	t.Helper()
+	fi, err := os.Lstat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Mode() & chmodBits
+}
+
+func TestApplyExpr(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{
+		"bin/":       0o700,
+		"bin/tool":   0o700,
+		"doc/":       0o750,
+		"doc/readme": 0o600,
+		"doc/shared": 0o666,
+	})
+	results, err := ApplyExpr(root, "a+rX,go-w")
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if len(results) != 6 {
+		t.Errorf("expected 6 results, got %d: %+v", len(results), results)
+	}
+	C := map[string]fs.FileMode{
+		"":           0o755,
+		"bin":        0o755,
+		"bin/tool":   0o755,
+		"doc":        0o755,
+		"doc/readme": 0o644,
+		"doc/shared": 0o644,
+	}
+	for name, want := range C {
+		if got := modeOf(t, filepath.Join(root, name)); got != want {
+			t.Errorf("with %q, expected %v. got %v", name, want, got)
+		}
+	}
+	for _, r := range results {
+		if r.Changed != (r.Before != r.After) {
+			t.Errorf("result %+v has inconsistent Changed", r)
+		}
+	}
+
+	results, err = ApplyExpr(root, "a+rX,go-w")
+	if err != nil {
+		t.Fatalf("got error on second application: %v", err)
+	}
+	for _, r := range results {
+		if r.Changed {
+			t.Errorf("expected second application to change nothing, but %s changed", r.Path)
+		}
+	}
+}
+
+func TestApplyExprNumericAndSkip(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{
+		"a":         0o600,
+		"uploads/":  0o777,
+		"uploads/x": 0o666,
+	})
+	if err := os.Symlink("a", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ApplyExpr(root, "0750", SkipPaths(func(path string, d fs.DirEntry) bool {
+		return d.Name() == "uploads"
+	}))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	C := map[string]fs.FileMode{
+		"":          0o750,
+		"a":         0o750,
+		"uploads":   0o777,
+		"uploads/x": 0o666,
+	}
+	for name, want := range C {
+		if got := modeOf(t, filepath.Join(root, name)); got != want {
+			t.Errorf("with %q, expected %v. got %v", name, want, got)
+		}
+	}
+}
+
+func TestApplyExprInvalid(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o600})
+	if _, err := ApplyExpr(root, "u+q"); err == nil {
+		t.Errorf("expected error for invalid expression")
+	}
+	if got := modeOf(t, filepath.Join(root, "a")); got != 0o600 {
+		t.Errorf("invalid expression changed mode to %v", got)
+	}
+	results, err := ApplyExpr(filepath.Join(root, "missing"), "u+x")
+	if err == nil || len(results) != 1 || results[0].Err == nil {
+		t.Errorf("expected a single error result for a missing root, got %+v, %v", results, err)
+	}
+}
+
+func TestApplyExprDryRun(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{
+		"a":  0o600,
+		"d/": 0o700,
+	})
+	results, err := ApplyExpr(root, "go+rX", DryRun())
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	planned := map[string]Perm{}
+	for _, r := range results {
+		if !r.DryRun {
+			t.Errorf("expected result for %s to be marked as a dry run", r.Path)
+		}
+		if r.Changed {
+			planned[r.Path] = r.After
+		}
+	}
+	if p := planned[filepath.Join(root, "a")]; p != 0o644 {
+		t.Errorf("expected planned mode 0644 for a, got %v", p)
+	}
+	if p := planned[filepath.Join(root, "d")]; p != Perm(fs.ModeDir|0o755) {
+		t.Errorf("expected planned mode 0755 for d, got %v", p)
+	}
+	if got := modeOf(t, filepath.Join(root, "a")); got != 0o600 {
+		t.Errorf("dry run changed mode of a to %v", got)
+	}
+	if got := modeOf(t, filepath.Join(root, "d")); got != 0o700 {
+		t.Errorf("dry run changed mode of d to %v", got)
+	}
+}
+
+func TestApplyExprJournal(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{
+		"a": 0o600,
+		"b": 0o640,
+	})
+	var buf bytes.Buffer
+	results, err := ApplyExpr(root, "g-r", WithJournal(&buf))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	dec := json.NewDecoder(&buf)
+	var entries []JournalEntry
+	for dec.More() {
+		var e JournalEntry
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("got error decoding journal: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 journal entry, got %+v", entries)
+	}
+	e := entries[0]
+	if e.Path != filepath.Join(root, "b") || e.Before != 0o640 || e.After != 0o600 || e.Rule != "g-r" || e.DryRun {
+		t.Errorf("unexpected journal entry %+v", e)
+	}
+	for _, r := range results {
+		if r.Path == e.Path && !r.Time.Equal(e.Time) {
+			t.Errorf("journal time %v does not match result time %v", e.Time, r.Time)
+		}
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestApplyExprJournalFailure(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{
+		"a/":  0o700,
+		"a/b": 0o600,
+	})
+	if _, err := ApplyExpr(root, "go+r", WithJournal(failingWriter{})); err == nil {
+		t.Fatalf("expected error when journal cannot be written")
+	}
+	if got := modeOf(t, filepath.Join(root, "a", "b")); got != 0o600 {
+		t.Errorf("walk continued after journal failure, changing a/b to %v", got)
+	}
+}
+
+func TestApplyExprLogger(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o600, "b": 0o644})
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	if _, err := ApplyExpr(root, "a+r", WithLogger(logger)); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	var records []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	}
+	// the root (0700) and a (0600) change, b does not
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %v", records)
+	}
+	r := records[1]
+	if r["msg"] != "mode changed" || r["level"] != "INFO" || r["path"] != filepath.Join(root, "a") ||
+		r["before"] != "-rw-------" || r["after"] != "-rw-r--r--" || r["rule"] != "a+r" || r["dry_run"] != false {
+		t.Errorf("unexpected record %v", r)
+	}
+
+	buf.Reset()
+	missing := filepath.Join(root, "missing")
+	if _, err := ApplyExpr(missing, "a+r", WithLogger(logger)); err == nil {
+		t.Fatalf("expected error for missing root")
+	}
+	if err := json.Unmarshal(buf.Bytes(), &r); err != nil {
+		t.Fatal(err)
+	}
+	if r["msg"] != "mode change failed" || r["level"] != "ERROR" || r["path"] != missing || r["error"] == nil {
+		t.Errorf("unexpected record %v", r)
+	}
+}
+
+func TestApplyExprContext(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o600, "b": 0o600, "c": 0o600})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// cancel while visiting b, so that the walk stops before c
+	stop := SkipPaths(func(path string, d fs.DirEntry) bool {
+		if filepath.Base(path) == "b" {
+			cancel()
+		}
+		return false
+	})
+	results, err := ApplyExprContext(ctx, root, "a+r", stop)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if len(results) != 3 || modeOf(t, filepath.Join(root, "c")) != 0o600 {
+		t.Errorf("expected the walk to stop after b, got %+v", results)
+	}
+}
+
+func TestApplyExprHardLinks(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o600, "b/": 0o755})
+	if err := os.Link(filepath.Join(root, "a"), filepath.Join(root, "b", "c")); err != nil {
+		t.Skipf("cannot create hard link: %v", err)
+	}
+	results, err := ApplyExpr(root, "go+r", DryRun())
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	var changed int
+	for _, r := range results {
+		if r.Changed {
+			changed++
+		}
+		switch r.Path {
+		case filepath.Join(root, "a"):
+			if r.Links != 2 || r.LinkedTo != "" || !r.Changed {
+				t.Errorf("expected first link to be changed and report 2 links, got %+v", r)
+			}
+		case filepath.Join(root, "b", "c"):
+			if r.Links != 2 || r.LinkedTo != filepath.Join(root, "a") || r.Changed {
+				t.Errorf("expected second link to refer to the first, got %+v", r)
+			}
+		}
+	}
+	if changed != 2 {
+		t.Errorf("expected the root and one link to change, got %+v", results)
+	}
+	s, err := Stats(os.DirFS(root))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if s.HardLinked != 2 {
+		t.Errorf("expected 2 hard linked entries, got %+v", s)
+	}
+}
@@ -0,0 +1,148 @@
+package posixperm
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// Normalizer returns the mode to record for an archive member with mode p, where isDir is set for
+// directories. See Clamp.
+type Normalizer func(p Perm, isDir bool) Perm
+
+// Clamp returns a Normalizer limiting files to the bits of file and directories to the bits of dir,
+// eg Clamp(0o644, 0o755) for a conventional release tarball, or Clamp(0o755, 0o755) to keep the
+// execute bits of files. Special bits are removed unless the limit includes them, so setuid and
+// setgid programs cannot slip into an archive unnoticed.
+func Clamp(file, dir Perm) Normalizer {
+	return func(p Perm, isDir bool) Perm {
+		if isDir {
+			return p & dir & Perm(chmodBits)
+		}
+		return p & file & Perm(chmodBits)
+	}
+}
+
+// NormalizeForReproducibleBuild returns the mode a reproducible build should record for a member with
+// mode p, following reproducible-builds.org guidance that archive contents must not depend on the
+// umask of the build host: directories are 0755, files with any execute bit are 0755, and other files
+// are 0644. Special and unknown bits are always cleared. It is a Normalizer, so it can be passed to
+// NewTarWriter directly.
+func NormalizeForReproducibleBuild(p Perm, isDir bool) Perm {
+	if isDir || p&0o111 != 0 {
+		return 0o755
+	}
+	return 0o644
+}
+
+// TarWriter is a tar.Writer that normalizes the mode of every member written through it except
+// symbolic links, whose modes are meaningless, for release tooling that must produce deterministic and
+// safe tarballs. Members of every other type are normalized, including those with an unset Typeflag,
+// which tar.Writer writes as regular files, so that no setuid program can slip through.
+type TarWriter struct {
+	*tar.Writer
+	normalize Normalizer
+}
+
+// NewTarWriter returns a TarWriter writing to w, recording the modes returned by normalize, such as
+// NormalizeForReproducibleBuild.
+func NewTarWriter(w io.Writer, normalize Normalizer) *TarWriter {
+	return &TarWriter{Writer: tar.NewWriter(w), normalize: normalize}
+}
+
+// WriteHeader writes hdr like tar.Writer's WriteHeader, but with its mode normalized. hdr itself is
+// not modified.
+func (tw *TarWriter) WriteHeader(hdr *tar.Header) error {
+	if hdr.Typeflag == tar.TypeSymlink {
+		return tw.Writer.WriteHeader(hdr)
+	}
+	h := *hdr
+	// an unset Typeflag (the deprecated TypeRegA) is a directory if the name ends in a slash, as it is
+	// for tar.Writer
+	isDir := h.Typeflag == tar.TypeDir || h.Typeflag == 0 && strings.HasSuffix(h.Name, "/")
+	p := tw.normalize(fromUnix(uint64(h.Mode)&0o7777), isDir)
+	h.Mode = h.Mode&^0o7777 | int64(unixMode(p&Perm(chmodBits)))
+	return tw.Writer.WriteHeader(&h)
+}
+
+// the creator host systems, in the high byte of a zip CreatorVersion, whose external attributes carry
+// a Unix mode
+const (
+	zipCreatorUnix  = 3
+	zipCreatorMacOS = 19
+)
+
+// SetZipMode records p as the mode of h, like zip.FileHeader's SetMode, after checking that it can be
+// represented: only permission bits, special bits, and the directory and symbolic link types are
+// allowed. A directory must also have a name ending in `/`, which is how unzip implementations
+// recognize it. See CheckZipMode.
+func SetZipMode(h *zip.FileHeader, p Perm) error {
+	m := fs.FileMode(p)
+	if t := m.Type(); t != 0 && t != fs.ModeDir && t != fs.ModeSymlink {
+		return fmt.Errorf("%s: file type %v cannot be stored in a zip archive", h.Name, t)
+	}
+	if isDir := strings.HasSuffix(h.Name, "/"); isDir != m.IsDir() {
+		return fmt.Errorf("%s: a directory must have a name ending in / and mode carrying fs.ModeDir", h.Name)
+	}
+	h.SetMode(m)
+	return nil
+}
+
+// CheckZipMode reports the reasons common unzip implementations will not restore the mode recorded in
+// h as it is, joined into one error, or nil if there are none. Modes are only honored when the creator
+// host is Unix or macOS and a Unix mode is present in the external attributes; Info-ZIP unzip
+// additionally drops the setuid, setgid, and sticky bits unless run with -K. Some extractors, such as
+// those built into desktop environments, ignore modes altogether, which cannot be detected.
+func CheckZipMode(h *zip.FileHeader) error {
+	var errs []error
+	switch host := h.CreatorVersion >> 8; {
+	case host != zipCreatorUnix && host != zipCreatorMacOS:
+		errs = append(errs, fmt.Errorf("%s: creator host %d is not Unix, so the mode is ignored", h.Name, host))
+	case h.ExternalAttrs>>16 == 0:
+		errs = append(errs, fmt.Errorf("%s: no Unix mode is recorded", h.Name))
+	default:
+		if m := h.Mode(); m&(fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky) != 0 {
+			errs = append(errs, fmt.Errorf("%s: special bits of %v are dropped by unzip without -K", h.Name, m))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ZipWriter is a zip.Writer that normalizes the mode of every file and directory created through it,
+// as TarWriter does for tar. Symbolic links keep their mode.
+type ZipWriter struct {
+	*zip.Writer
+	normalize Normalizer
+}
+
+// NewZipWriter returns a ZipWriter writing to w, recording the modes returned by normalize, such as
+// NormalizeForReproducibleBuild.
+func NewZipWriter(w io.Writer, normalize Normalizer) *ZipWriter {
+	return &ZipWriter{Writer: zip.NewWriter(w), normalize: normalize}
+}
+
+// Create adds a file to the archive like zip.Writer's Create, but with its mode normalized.
+func (zw *ZipWriter) Create(name string) (io.Writer, error) {
+	return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+}
+
+// CreateHeader adds a file described by fh like zip.Writer's CreateHeader, but with its mode
+// normalized and recorded in the form unzip implementations honor. A header without a Unix mode, such
+// as one built from a name alone, has the mode zip.FileHeader's Mode derives from its MS-DOS
+// attributes: 0666, or 0444 if they mark it read-only, or a directory with mode 0777 if they mark it
+// one. A name ending in `/` also makes it a directory. It is given whatever the Normalizer returns for
+// that. Like zip.Writer, it modifies fh.
+func (zw *ZipWriter) CreateHeader(fh *zip.FileHeader) (io.Writer, error) {
+	m := fh.Mode()
+	if m.Type() != fs.ModeSymlink {
+		p := zw.normalize(Perm(m&chmodBits), m.IsDir())
+		if err := SetZipMode(fh, Perm(m.Type())|p&Perm(chmodBits)); err != nil {
+			return nil, err
+		}
+	}
+	return zw.Writer.CreateHeader(fh)
+}
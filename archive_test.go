@@ -0,0 +1,197 @@
+package posixperm
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestClamp(t *testing.T) {
+	n := Clamp(0o644, 0o755)
+	C := []struct {
+		p     Perm
+		isDir bool
+		want  Perm
+	}{
+		{0o600, false, 0o600},
+		{0o777, false, 0o644},
+		{Perm(fs.ModeSetuid) | 0o755, false, 0o644},
+		{0o777, true, 0o755},
+		{Perm(fs.ModeSetgid|fs.ModeSticky) | 0o2775, true, 0o755},
+	}
+	for _, c := range C {
+		if got := n(c.p, c.isDir); got != c.want {
+			t.Errorf("with %v (dir %v), expected %v. got %v", c.p, c.isDir, c.want, got)
+		}
+	}
+}
+
+func TestNormalizeForReproducibleBuild(t *testing.T) {
+	C := []struct {
+		p     Perm
+		isDir bool
+		want  Perm
+	}{
+		{0o600, false, 0o644},
+		{0o664, false, 0o644},
+		{0o700, false, 0o755},
+		{0o701, false, 0o755},
+		{Perm(fs.ModeSetuid) | 0o750, false, 0o755},
+		{0o700, true, 0o755},
+		{Perm(fs.ModeDir|fs.ModeSticky) | 0o777, true, 0o755},
+	}
+	for _, c := range C {
+		if got := NormalizeForReproducibleBuild(c.p, c.isDir); got != c.want {
+			t.Errorf("with %v (dir %v), expected %v. got %v", c.p, c.isDir, c.want, got)
+		}
+	}
+}
+
+func TestTarWriter(t *testing.T) {
+	var buf bytes.Buffer
+	tw := NewTarWriter(&buf, Clamp(0o644, 0o755))
+	headers := []*tar.Header{
+		{Name: "bin/", Typeflag: tar.TypeDir, Mode: 0o2777},
+		{Name: "bin/tool", Typeflag: tar.TypeReg, Mode: 0o4755},
+		{Name: "README", Typeflag: tar.TypeReg, Mode: 0o600},
+		{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "README", Mode: 0o777},
+		{Name: "bin/untyped", Mode: 0o4777},
+		{Name: "share/", Mode: 0o2777},
+		{Name: "hard", Typeflag: tar.TypeLink, Linkname: "README", Mode: 0o6755},
+	}
+	for _, h := range headers {
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if headers[1].Mode != 0o4755 {
+		t.Errorf("expected header not to be modified, got mode %o", headers[1].Mode)
+	}
+	want := map[string]int64{"bin/": 0o755, "bin/tool": 0o644, "README": 0o600, "link": 0o777,
+		"bin/untyped": 0o644, "share/": 0o755, "hard": 0o644}
+	tr := tar.NewReader(&buf)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Mode != want[h.Name] {
+			t.Errorf("with %q, expected mode %o. got %o", h.Name, want[h.Name], h.Mode)
+		}
+	}
+}
+
+func TestSetZipMode(t *testing.T) {
+	C := []struct {
+		name string
+		p    Perm
+		ok   bool
+	}{
+		{"a", 0o644, true},
+		{"bin/", Perm(fs.ModeDir) | 0o755, true},
+		{"link", Perm(fs.ModeSymlink) | 0o777, true},
+		{"bin", Perm(fs.ModeDir) | 0o755, false},
+		{"a/", 0o644, false},
+		{"fifo", Perm(fs.ModeNamedPipe) | 0o644, false},
+	}
+	for _, c := range C {
+		h := &zip.FileHeader{Name: c.name}
+		err := SetZipMode(h, c.p)
+		if c.ok && (err != nil || h.Mode() != fs.FileMode(c.p)) {
+			t.Errorf("with %q and %v, expected mode to be recorded, got %v, %v", c.name, c.p, h.Mode(), err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %q and %v, expected error", c.name, c.p)
+		}
+	}
+}
+
+func TestCheckZipMode(t *testing.T) {
+	unix := func(p Perm) *zip.FileHeader {
+		h := &zip.FileHeader{Name: "f"}
+		h.SetMode(fs.FileMode(p))
+		return h
+	}
+	C := []struct {
+		h    *zip.FileHeader
+		want string
+	}{
+		{unix(0o644), ""},
+		{unix(Perm(fs.ModeSetuid) | 0o755), "dropped by unzip without -K"},
+		{&zip.FileHeader{Name: "f"}, "creator host 0 is not Unix"},
+		{&zip.FileHeader{Name: "f", CreatorVersion: zipCreatorUnix << 8}, "no Unix mode"},
+	}
+	for _, c := range C {
+		err := CheckZipMode(c.h)
+		if c.want == "" && err != nil {
+			t.Errorf("with %v, expected no warning, got %v", c.h.Mode(), err)
+		}
+		if c.want != "" && (err == nil || !strings.Contains(err.Error(), c.want)) {
+			t.Errorf("with %v, expected warning containing %q, got %v", c.h.Mode(), c.want, err)
+		}
+	}
+}
+
+func TestZipWriter(t *testing.T) {
+	var buf bytes.Buffer
+	zw := NewZipWriter(&buf, NormalizeForReproducibleBuild)
+	if _, err := zw.Create("README"); err != nil {
+		t.Fatal(err)
+	}
+	h := &zip.FileHeader{Name: "bin/"}
+	h.SetMode(fs.ModeDir | 0o700)
+	if _, err := zw.CreateHeader(h); err != nil {
+		t.Fatal(err)
+	}
+	h = &zip.FileHeader{Name: "bin/tool"}
+	h.SetMode(fs.ModeSetuid | 0o700)
+	if _, err := zw.CreateHeader(h); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]fs.FileMode{"README": 0o644, "bin/": fs.ModeDir | 0o755, "bin/tool": 0o755}
+	for _, f := range zr.File {
+		if f.Mode() != want[f.Name] {
+			t.Errorf("with %q, expected %v. got %v", f.Name, want[f.Name], f.Mode())
+		}
+		if err := CheckZipMode(&f.FileHeader); err != nil {
+			t.Errorf("with %q, got warning: %v", f.Name, err)
+		}
+	}
+
+	buf.Reset()
+	zw = NewZipWriter(&buf, func(p Perm, _ bool) Perm { return p })
+	for _, name := range []string{"f", "d/"} {
+		if _, err := zw.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if zr, err = zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Fatal(err)
+	}
+	want = map[string]fs.FileMode{"f": 0o666, "d/": fs.ModeDir | 0o666}
+	for _, f := range zr.File {
+		if f.Mode() != want[f.Name] {
+			t.Errorf("without a unix mode, with %q, expected %v. got %v", f.Name, want[f.Name], f.Mode())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package posixperm
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+)
+
+// Finding is a problem with the mode of a path, as reported by an audit.
+type Finding struct {
+	Path    string
+	Mode    Perm
+	Problem string
+}
+
+func (f Finding) String() string {
+	return f.Path + " (" + f.Mode.String() + "): " + f.Problem
+}
+
+// AuditTar reads the tar archive from r without extracting it and reports every member whose mode
+// does not satisfy m, such as release artifacts or container layers holding world writable files or
+// stray setuid programs before they are published:
+//
+//	findings, err := posixperm.AuditTar(r, posixperm.AtMost(0o755))
+//
+// m is given the permission and special bits of each member, so that AtMost(0o755) accepts
+// directories; each Finding's Mode also carries the file type. Symbolic links are not checked, as
+// their modes are not used. For a compressed archive, wrap r in a decompressor such as
+// gzip.NewReader. If the archive cannot be read, the findings so far are returned with the error.
+func AuditTar(r io.Reader, m Matcher) ([]Finding, error) {
+	var findings []Finding
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return findings, nil
+		}
+		if err != nil {
+			return findings, err
+		}
+		findings = auditMember(findings, h.Name, h.FileInfo().Mode(), m)
+	}
+}
+
+// AuditZip is like AuditTar for the zip archive read by r. Members without a Unix mode are checked
+// with the mode zip.FileHeader's Mode derives from their MS-DOS attributes.
+func AuditZip(r *zip.Reader, m Matcher) []Finding {
+	var findings []Finding
+	for _, f := range r.File {
+		findings = auditMember(findings, f.Name, f.Mode(), m)
+	}
+	return findings
+}
+
+// auditMember appends a Finding to findings if the archive member name, with mode mode, is not a
+// symbolic link and does not satisfy m.
+func auditMember(findings []Finding, name string, mode fs.FileMode, m Matcher) []Finding {
+	if mode.Type() == fs.ModeSymlink || m.Match(Perm(mode&^fs.ModeType)) {
+		return findings
+	}
+	return append(findings, Finding{Path: name, Mode: Perm(mode), Problem: "mode does not satisfy " + m.String()})
+}
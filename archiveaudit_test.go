@@ -0,0 +1,78 @@
+package posixperm
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func TestAuditTar(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	C := []tar.Header{
+		{Name: "bin/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "bin/tool", Typeflag: tar.TypeReg, Mode: 0o4755},
+		{Name: "etc/app.conf", Typeflag: tar.TypeReg, Mode: 0o666},
+		{Name: "README", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "current", Typeflag: tar.TypeSymlink, Linkname: "bin", Mode: 0o777},
+	}
+	for i := range C {
+		if err := tw.WriteHeader(&C[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, err := AuditTar(r, AtMost(0o755))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	want := []Finding{
+		{"bin/tool", Perm(fs.ModeSetuid | 0o755), "mode does not satisfy <=0755"},
+		{"etc/app.conf", 0o666, "mode does not satisfy <=0755"},
+	}
+	if !reflect.DeepEqual(findings, want) {
+		t.Errorf("expected %v, got %v", want, findings)
+	}
+
+	if _, err := AuditTar(bytes.NewReader(buf.Bytes()), AtMost(0o755)); err == nil {
+		t.Errorf("expected error for a compressed archive read as is")
+	}
+}
+
+func TestAuditZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	C := map[string]fs.FileMode{"bin/": fs.ModeDir | 0o777, "bin/tool": 0o755, "lib.so": 0o644}
+	for name, mode := range C {
+		h := &zip.FileHeader{Name: name}
+		h.SetMode(mode)
+		if _, err := zw.CreateHeader(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Finding{{"bin/", Perm(fs.ModeDir | 0o777), "mode does not satisfy <=0775"}}
+	if findings := AuditZip(zr, AtMost(0o775)); !reflect.DeepEqual(findings, want) {
+		t.Errorf("expected %v, got %v", want, findings)
+	}
+}
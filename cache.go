@@ -0,0 +1,48 @@
+package posixperm
+
+import "sync"
+
+// WithCache makes a Parser remember the results of up to size distinct inputs, for workloads that
+// parse the same few strings (eg `0644` in every row of a manifest) very many times. Only successful
+// results are cached. When the cache is full it is emptied and starts again, which keeps its cost
+// predictable on untrusted input. A size of 0 or less disables caching, which is the default.
+//
+// The cache belongs to the Parser built with the option, including the default Parser; FromString
+// and ParseList given further Options do not use it. Entries are keyed on the input as given, before
+// any tidying by WithLenient.
+func WithCache(size int) Option {
+	return func(o *options) {
+		o.cache = nil
+		if size > 0 {
+			o.cache = &parseCache{size: size}
+		}
+	}
+}
+
+type cacheEntry struct {
+	p Perm
+	f Format
+}
+
+// parseCache is a bounded map of inputs to their parsed values, safe for concurrent use.
+type parseCache struct {
+	mu      sync.RWMutex
+	size    int
+	entries map[string]cacheEntry
+}
+
+func (c *parseCache) get(b []byte) (cacheEntry, bool) {
+	c.mu.RLock()
+	e, ok := c.entries[string(b)]
+	c.mu.RUnlock()
+	return e, ok
+}
+
+func (c *parseCache) put(b []byte, e cacheEntry) {
+	c.mu.Lock()
+	if c.entries == nil || len(c.entries) >= c.size {
+		c.entries = make(map[string]cacheEntry, c.size)
+	}
+	c.entries[string(b)] = e
+	c.mu.Unlock()
+}
@@ -0,0 +1,85 @@
+package posixperm
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	ps := NewParser(WithCache(2), WithMaxMode(0o755))
+	C := []struct {
+		s  string
+		v  Perm
+		ok bool
+	}{
+		{"0644", 0o644, true},
+		{"0644", 0o644, true},
+		{"0777", 0, false},
+		{"0777", 0, false},
+		{"rwxr-x---", 0o750, true},
+		{"a=rx u+w", 0o755, true},
+		{"0644", 0o644, true},
+	}
+	for _, c := range C {
+		p, err := ps.Parse(c.s)
+		if c.ok && (err != nil || p != c.v) {
+			t.Errorf("with %q, expected %v. got %v, %v", c.s, c.v, p, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %q, expected error. got %v", c.s, p)
+		}
+	}
+	if n := len(ps.opts.cache.entries); n > 2 {
+		t.Errorf("expected at most 2 cached entries, got %d", n)
+	}
+	if ps := NewParser(WithCache(8), WithCache(0)); ps.opts.cache != nil {
+		t.Errorf("expected WithCache(0) to disable caching")
+	}
+}
+
+func TestCacheDefaultParser(t *testing.T) {
+	defer defaultParser.Store(nil)
+	if err := SetDefaultParser(NewParser(WithCache(16))); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := FromString("0777"); err != nil || p != 0o777 {
+		t.Fatalf("expected 0777, got %v, %v", p, err)
+	}
+	if _, err := FromString("0777", WithMaxMode(0o755)); err == nil {
+		t.Errorf("expected options given to FromString to bypass the cache")
+	}
+	if _, err := ParseList([]string{"0777"}, WithMaxMode(0o755)); err == nil {
+		t.Errorf("expected options given to ParseList to bypass the cache")
+	}
+	if _, err := ParseList([]string{"7"}, WithLenient()); err != nil {
+		t.Fatalf("got error parsing short octal leniently: %v", err)
+	}
+	var p Perm
+	if err := p.UnmarshalText([]byte("7")); err == nil {
+		t.Errorf("expected lenient ParseList not to store short octal in the shared cache, got %v", p)
+	}
+}
+
+func TestCacheLenientKey(t *testing.T) {
+	ps := NewParser(WithCache(4), WithLenient())
+	for i := 0; i < 2; i++ {
+		if p, err := ps.Parse(" u+r,, g+r "); err != nil || p != 0o440 {
+			t.Fatalf("expected 0440, got %v, %v", p, err)
+		}
+	}
+	if _, ok := ps.opts.cache.get([]byte(" u+r,, g+r ")); !ok {
+		t.Errorf("expected lenient input to be cached as given")
+	}
+}
+
+func BenchmarkCache(b *testing.B) {
+	inputs := []string{"0644", "0755", "-rw-r--r--", "a=rx u+w", "u=rw g=r o=r"}
+	for _, size := range []int{0, 16} {
+		ps := NewParser(WithCache(size))
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ps.Parse(inputs[i%len(inputs)])
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package posixperm
+
+import "fmt"
+
+// cannedACLs lists the conventional mode of each supported canned ACL, most restrictive first.
+var cannedACLs = []struct {
+	name string
+	perm Perm
+}{
+	{"private", 0o600},
+	{"bucket-owner-read", 0o640},
+	{"bucket-owner-full-control", 0o660},
+	{"authenticated-read", 0o640},
+	{"public-read", 0o644},
+	{"public-read-write", 0o666},
+}
+
+// FromCannedACL returns the mode conventionally corresponding to the named S3-style canned ACL. The
+// mapping is necessarily approximate: object stores have no execute permission or group ownership,
+// and their grantees do not line up with POSIX classes. The conventions adopted are:
+//
+//	private                   -- 0600, only the owner may read or write
+//	bucket-owner-read         -- 0640, the bucket owner is treated as the group
+//	bucket-owner-full-control -- 0660, as above with write
+//	authenticated-read        -- 0640, authenticated users are treated as the group
+//	public-read               -- 0644, anyone may read
+//	public-read-write         -- 0666, anyone may read or write
+//
+// ACLs with no reasonable equivalent, such as "aws-exec-read", are an error.
+func FromCannedACL(name string) (Perm, error) {
+	for _, c := range cannedACLs {
+		if c.name == name {
+			return c.perm, nil
+		}
+	}
+	return 0, fmt.Errorf("no permission equivalent for canned ACL %q", name)
+}
+
+// CannedACL returns the most restrictive S3-style canned ACL that grants at least the read and write
+// access p grants. Execute and special bits are ignored, as object stores have no equivalent, and
+// write access for other implies "public-read-write" even when read is not granted. Because
+// bucket-owner-read and authenticated-read share a mode, the former is always chosen.
+func (p Perm) CannedACL() string {
+	rw := p & 0o666
+	switch {
+	case rw&0o002 != 0:
+		return "public-read-write"
+	case rw&0o004 != 0:
+		return "public-read"
+	case rw&0o020 != 0:
+		return "bucket-owner-full-control"
+	case rw&0o040 != 0:
+		return "bucket-owner-read"
+	}
+	return "private"
+}
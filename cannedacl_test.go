@@ -0,0 +1,54 @@
+package posixperm
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestFromCannedACL(t *testing.T) {
+	C := []struct {
+		name string
+		v    Perm
+	}{
+		{"private", 0o600},
+		{"public-read", 0o644},
+		{"public-read-write", 0o666},
+		{"authenticated-read", 0o640},
+		{"bucket-owner-read", 0o640},
+		{"bucket-owner-full-control", 0o660},
+	}
+	for _, c := range C {
+		p, err := FromCannedACL(c.name)
+		if err != nil || p != c.v {
+			t.Errorf("with %q, expected %v. got %v, %v", c.name, c.v, p, err)
+		}
+	}
+	for _, name := range []string{"aws-exec-read", "", "Private"} {
+		if p, err := FromCannedACL(name); err == nil {
+			t.Errorf("got nil error for %q, mapped to %v", name, p)
+		}
+	}
+}
+
+func TestCannedACL(t *testing.T) {
+	C := []struct {
+		p Perm
+		v string
+	}{
+		{0o600, "private"},
+		{0o700, "private"},
+		{0o000, "private"},
+		{0o640, "bucket-owner-read"},
+		{0o750, "bucket-owner-read"},
+		{0o660, "bucket-owner-full-control"},
+		{0o644, "public-read"},
+		{Perm(fs.ModeDir | 0o755), "public-read"},
+		{0o666, "public-read-write"},
+		{0o602, "public-read-write"},
+	}
+	for _, c := range C {
+		if v := c.p.CannedACL(); v != c.v {
+			t.Errorf("with %v, expected %q. got %q", c.p, c.v, v)
+		}
+	}
+}
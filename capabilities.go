@@ -0,0 +1,41 @@
+package posixperm
+
+// Capabilities describes the features compiled into this version of the package, so that tools
+// embedding it can be feature-detected by orchestration layers. It marshals to JSON with the syntax
+// names returned by Format's String. See ListCapabilities.
+type Capabilities struct {
+	// Syntaxes lists every syntax a permission may be parsed from, including those that must be
+	// enabled with an option such as WithLenient.
+	Syntaxes []Format `json:"syntaxes"`
+	// Styles lists every syntax FormatAs can render in.
+	Styles []Format `json:"styles"`
+	// Matchers lists the Matcher constructors, by the operator they render with, eg `<=`.
+	Matchers []string `json:"matchers"`
+	// Checks lists the built-in policy checks.
+	Checks []string `json:"checks"`
+}
+
+// ListCapabilities returns the Capabilities of this version of the package. The slices are freshly
+// allocated and may be modified by the caller.
+func ListCapabilities() Capabilities {
+	var c Capabilities
+	for f := FormatUnknown + 1; f < formatCount; f++ {
+		c.Syntaxes = append(c.Syntaxes, f)
+		if _, err := Perm(0).FormatAs(f); err == nil {
+			c.Styles = append(c.Styles, f)
+		}
+	}
+	c.Matchers = []string{"<=", ">=", "==", "{|}", "!", "&&", "||"}
+	c.Checks = []string{
+		"ssh-key-path",             // CheckSSHKeyPath
+		"sudoers",                  // CheckSudoersFile
+		"cron-fragment",            // CheckCronFragment
+		"home-audit",               // AuditHomes
+		"archive-audit",            // AuditTar and AuditZip
+		"image-audit",              // AuditImage and AuditImageArchive
+		"secret-file",              // SecretFile and RequirePrivate
+		"setgid-tree",              // AuditSetgidTree
+		"world-writable-no-sticky", // WorldWritableNoSticky
+	}
+	return c
+}
@@ -0,0 +1,30 @@
+package posixperm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestListCapabilities(t *testing.T) {
+	c := ListCapabilities()
+	if len(c.Syntaxes) != int(formatCount)-1 {
+		t.Errorf("expected %d syntaxes, got %v", int(formatCount)-1, c.Syntaxes)
+	}
+	for _, f := range c.Styles {
+		if _, err := Perm(0o755).FormatAs(f); err != nil && f != FormatBasicSingle && f != FormatShortOctal {
+			t.Errorf("with style %v, got error rendering: %v", f, err)
+		}
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d Capabilities
+	if err := json.Unmarshal(b, &d); err != nil {
+		t.Fatalf("got error unmarshaling %s: %v", b, err)
+	}
+	if !reflect.DeepEqual(c, d) {
+		t.Errorf("expected %+v to survive a JSON round trip, got %+v", c, d)
+	}
+}
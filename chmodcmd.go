@@ -0,0 +1,101 @@
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// a chmod(1) numeric mode, where special bits use the traditional unix encoding (eg "4755")
+var fmtChmodOctal = regexp.MustCompile(`^0*[0-7]{1,4}$`)
+
+// a chmod(1) symbolic mode, with comma separated clauses (eg "u+x,go=rX" or "g=u")
+var fmtChmodSymbolic = regexp.MustCompile(`^[ugoa]*([-+=]([rwxXst]*|[ugo]))+(,[ugoa]*([-+=]([rwxXst]*|[ugo]))+)*$`)
+
+// ChmodCommand is a chmod(1) invocation, as extracted from a shell script. See ParseChmodCommand.
+type ChmodCommand struct {
+	// Mode is the mode operand as written.
+	Mode string
+	// Absolute is set if Mode is numeric, so the resulting mode does not depend on the existing
+	// mode of each target, with one exception: as in GNU chmod, a numeric mode of fewer than 5
+	// digits leaves the setuid and setgid bits of a directory as they were. Symbolic modes are
+	// generally relative.
+	Absolute bool
+	// Perm is the mode that will be set, if Absolute is set. For a directory, its existing setuid
+	// and setgid bits are kept unless Mode has 5 or more digits.
+	Perm Perm
+	// Recursive is set if -R or --recursive was given.
+	Recursive bool
+	// Targets are the file operands.
+	Targets []string
+}
+
+// ParseChmodCommand parses a chmod(1) command line such as `chmod -R 0755 /srv/app` into its parts.
+// Words are split following shell quoting rules, but no expansion is performed, so variables and
+// globs are returned as written. Flags that do not affect the resulting modes (-c, -f, -v and their
+// long forms) are accepted and ignored; --reference and unknown flags are an error.
+func ParseChmodCommand(cmdline string) (ChmodCommand, error) {
+	args, err := splitShellWords(cmdline)
+	if err != nil {
+		return ChmodCommand{}, fmt.Errorf("cannot parse chmod command %q: %w", cmdline, err)
+	}
+	return ParseChmodArgs(args)
+}
+
+// ParseChmodArgs is like ParseChmodCommand but takes an already split argument vector, whose first
+// element is the command name.
+func ParseChmodArgs(args []string) (c ChmodCommand, err error) {
+	if len(args) == 0 || path.Base(args[0]) != "chmod" {
+		return c, errors.New("not a chmod command")
+	}
+	var operands []string
+	options := true
+	for _, a := range args[1:] {
+		switch {
+		case !options || a == "-" || !strings.HasPrefix(a, "-"):
+			operands = append(operands, a)
+		case a == "--":
+			options = false
+		case a == "--recursive":
+			c.Recursive = true
+		case a == "--changes" || a == "--silent" || a == "--quiet" || a == "--verbose" ||
+			a == "--preserve-root" || a == "--no-preserve-root":
+		case strings.HasPrefix(a, "--"):
+			return c, fmt.Errorf("unsupported chmod option %q", a)
+		case len(operands) == 0 && c.Mode == "" && fmtChmodSymbolic.MatchString(a):
+			// a mode such as "-w" or "-w,u+x" which looks like a flag
+			c.Mode = a
+		default:
+			for _, f := range a[1:] {
+				switch f {
+				case 'R':
+					c.Recursive = true
+				case 'c', 'f', 'v':
+				default:
+					return c, fmt.Errorf("unsupported chmod option %q", "-"+string(f))
+				}
+			}
+		}
+	}
+	if c.Mode == "" {
+		if len(operands) == 0 {
+			return c, errors.New("chmod command has no mode")
+		}
+		c.Mode, operands = operands[0], operands[1:]
+	}
+	if len(operands) == 0 {
+		return c, errors.New("chmod command has no targets")
+	}
+	c.Targets = operands
+	if fmtChmodOctal.MatchString(c.Mode) {
+		c.Perm, err = parseChmodMode(c.Mode)
+		c.Absolute = true
+		return c, err
+	}
+	if !fmtChmodSymbolic.MatchString(c.Mode) {
+		return c, fmt.Errorf("unrecognized chmod mode %q", c.Mode)
+	}
+	return c, nil
+}
@@ -0,0 +1,78 @@
+package posixperm
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func TestValidChmodCommand(t *testing.T) {
+	C := []struct {
+		s string
+		v ChmodCommand
+	}{
+		{"chmod 0755 /srv/app", ChmodCommand{Mode: "0755", Absolute: true, Perm: 0o755, Targets: []string{"/srv/app"}}},
+		{"chmod -R 644 a b", ChmodCommand{Mode: "644", Absolute: true, Perm: 0o644, Recursive: true, Targets: []string{"a", "b"}}},
+		{"/bin/chmod 4755 /usr/bin/tool", ChmodCommand{Mode: "4755", Absolute: true, Perm: Perm(fs.ModeSetuid | 0o755), Targets: []string{"/usr/bin/tool"}}},
+		{"chmod -Rv u+x,go=rX 'my dir'", ChmodCommand{Mode: "u+x,go=rX", Recursive: true, Targets: []string{"my dir"}}},
+		{"chmod --recursive g=u \"$DIR\"", ChmodCommand{Mode: "g=u", Recursive: true, Targets: []string{"$DIR"}}},
+		{"chmod -w file", ChmodCommand{Mode: "-w", Targets: []string{"file"}}},
+		{"chmod -w,u+x file", ChmodCommand{Mode: "-w,u+x", Targets: []string{"file"}}},
+		{"chmod -R -rwx,u=rw dir", ChmodCommand{Mode: "-rwx,u=rw", Recursive: true, Targets: []string{"dir"}}},
+		{"chmod -- -x -file", ChmodCommand{Mode: "-x", Targets: []string{"-file"}}},
+		{"chmod +t /tmp", ChmodCommand{Mode: "+t", Targets: []string{"/tmp"}}},
+		{"chmod 7 x", ChmodCommand{Mode: "7", Absolute: true, Perm: 0o007, Targets: []string{"x"}}},
+	}
+	for _, c := range C {
+		v, err := ParseChmodCommand(c.s)
+		if err != nil {
+			t.Errorf("with %q, expected %+v. got error: %v", c.s, c.v, err)
+		}
+		if !reflect.DeepEqual(v, c.v) {
+			t.Errorf("with %q, expected %+v. got %+v", c.s, c.v, v)
+		}
+	}
+}
+
+func TestInvalidChmodCommand(t *testing.T) {
+	C := []string{
+		"",
+		"chown 0755 x",
+		"chmod",
+		"chmod 0755",
+		"chmod --reference=a b",
+		"chmod -Z 0755 x",
+		"chmod 0855 x",
+		"chmod u+q x",
+		"chmod 0755 'unterminated",
+	}
+	for _, c := range C {
+		v, err := ParseChmodCommand(c)
+		if err == nil {
+			t.Errorf("got nil error for %q, parsed to %+v", c, v)
+		}
+	}
+}
+
+func TestSplitShellWords(t *testing.T) {
+	C := []struct {
+		s string
+		v []string
+	}{
+		{`a b  c`, []string{"a", "b", "c"}},
+		{`'a b' "c d"`, []string{"a b", "c d"}},
+		{`a\ b`, []string{"a b"}},
+		{`"a \"b\" \$c"`, []string{`a "b" $c`}},
+		{`''`, []string{""}},
+		{`x'y'"z"`, []string{"xyz"}},
+		{"a \\\n b", []string{"a", "b"}},
+		{"a\\\nb", []string{"ab"}},
+		{"\"a\\\nb\"", []string{"ab"}},
+	}
+	for _, c := range C {
+		v, err := splitShellWords(c.s)
+		if err != nil || !reflect.DeepEqual(v, c.v) {
+			t.Errorf("with %q, expected %q. got %q, %v", c.s, c.v, v, err)
+		}
+	}
+}
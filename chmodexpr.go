@@ -0,0 +1,142 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// the permission and special bits belonging to each class, as affected by chmod(1) "who" letters
+const (
+	whoUser  = 0o700 | fs.ModeSetuid
+	whoGroup = 0o070 | fs.ModeSetgid
+	whoOther = 0o007 | fs.ModeSticky
+	whoAll   = whoUser | whoGroup | whoOther
+)
+
+// chmodOp is a single operator and its operand within a chmod(1) symbolic clause.
+type chmodOp struct {
+	op    byte        // '+', '-', or '='
+	bits  fs.FileMode // fixed bits from r, w, x, s, and t
+	condX bool        // X: execute if a directory or if any execute bit is already set
+	copy  fs.FileMode // if nonzero, the class mask (0o700, 0o070, or 0o007) to copy rights from
+}
+
+// chmodClause is a set of classes and the operators applied to them, eg "go-w+X".
+type chmodClause struct {
+	who fs.FileMode
+	ops []chmodOp
+}
+
+// chmodExpr is a compiled chmod(1) symbolic mode, a list of comma separated clauses.
+type chmodExpr []chmodClause
+
+// compileChmodExpr compiles a chmod(1) symbolic mode such as "u+x,go=rX" or "g=u". A clause with no
+// who letters applies to all classes, as chmod does when the umask is 0.
+func compileChmodExpr(s string) (chmodExpr, error) {
+	if s == "" {
+		return nil, fmt.Errorf("empty symbolic mode")
+	}
+	var e chmodExpr
+	for _, clause := range strings.Split(s, ",") {
+		c, err := compileChmodClause(clause)
+		if err != nil {
+			return nil, fmt.Errorf("invalid symbolic mode %q: %w", s, err)
+		}
+		e = append(e, c)
+	}
+	return e, nil
+}
+
+func compileChmodClause(s string) (c chmodClause, err error) {
+	i := 0
+who:
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case 'u':
+			c.who |= whoUser
+		case 'g':
+			c.who |= whoGroup
+		case 'o':
+			c.who |= whoOther
+		case 'a':
+			c.who |= whoAll
+		default:
+			break who
+		}
+	}
+	if c.who == 0 {
+		c.who = whoAll
+	}
+	if i == len(s) {
+		return c, fmt.Errorf("clause %q has no operator", s)
+	}
+	for i < len(s) {
+		op := chmodOp{op: s[i]}
+		if op.op != '+' && op.op != '-' && op.op != '=' {
+			return c, fmt.Errorf("clause %q has unexpected %q", s, s[i])
+		}
+		i++
+		if i < len(s) && strings.IndexByte("ugo", s[i]) >= 0 {
+			op.copy = map[byte]fs.FileMode{'u': 0o700, 'g': 0o070, 'o': 0o007}[s[i]]
+			i++
+		} else {
+			for ; i < len(s) && strings.IndexByte("rwxXst", s[i]) >= 0; i++ {
+				switch s[i] {
+				case 'r':
+					op.bits |= 0o444
+				case 'w':
+					op.bits |= 0o222
+				case 'x':
+					op.bits |= 0o111
+				case 'X':
+					op.condX = true
+				case 's':
+					op.bits |= fs.ModeSetuid | fs.ModeSetgid
+				case 't':
+					op.bits |= fs.ModeSticky
+				}
+			}
+		}
+		c.ops = append(c.ops, op)
+	}
+	return c, nil
+}
+
+// apply returns the result of applying e to mode m, treating the file as a directory if isDir is
+// set. Like GNU chmod, "=" leaves the setuid and setgid bits of directories alone unless they are
+// explicitly mentioned. File type bits in m are preserved.
+func (e chmodExpr) apply(m fs.FileMode, isDir bool) fs.FileMode {
+	for _, c := range e {
+		for _, op := range c.ops {
+			bits := op.bits
+			if op.copy != 0 {
+				v := m & op.copy
+				switch op.copy {
+				case 0o700:
+					v >>= 6
+				case 0o070:
+					v >>= 3
+				}
+				bits = v | v<<3 | v<<6
+			}
+			if op.condX && (isDir || m&0o111 != 0) {
+				bits |= 0o111
+			}
+			bits &= c.who
+			switch op.op {
+			case '+':
+				m |= bits
+			case '-':
+				m &^= bits
+			case '=':
+				clear := c.who
+				if isDir {
+					clear &^= fs.ModeSetuid | fs.ModeSetgid
+				}
+				m = m&^clear | bits
+			}
+		}
+	}
+	return m
+}
@@ -0,0 +1,69 @@
+package posixperm
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestChmodExprApply(t *testing.T) {
+	const (
+		suid   = fs.ModeSetuid
+		sgid   = fs.ModeSetgid
+		sticky = fs.ModeSticky
+	)
+	C := []struct {
+		expr  string
+		base  fs.FileMode
+		isDir bool
+		v     fs.FileMode
+	}{
+		{"u+x", 0o644, false, 0o744},
+		{"go-w", 0o777, false, 0o755},
+		{"a=r", 0o777, false, 0o444},
+		{"=r", 0o777, false, 0o444},
+		{"u=rwx,go=rx", 0, false, 0o755},
+		{"u+r-w", 0o200, false, 0o400},
+		{"a+X", 0o644, false, 0o644},
+		{"a+X", 0o744, false, 0o755},
+		{"a+X", 0o600, true, 0o711},
+		{"g=u", 0o640, false, 0o660},
+		{"o=g", 0o751, false, 0o755},
+		{"go=u-w", 0o700, false, 0o755},
+		{"u+s", 0o755, false, suid | 0o755},
+		{"g+s", 0o755, true, sgid | 0o755},
+		{"+s", 0o755, false, suid | sgid | 0o755},
+		{"o+s", 0o755, false, 0o755},
+		{"+t", 0o777, true, sticky | 0o777},
+		{"u+t", 0o777, true, 0o777},
+		{"g=rx", sgid | 0o775, true, sgid | 0o755},
+		{"g=rx", sgid | 0o775, false, 0o755},
+		{"g-s", sgid | 0o775, true, 0o775},
+		{"a=", 0o777, false, 0},
+		{"u+x", fs.ModeDir | 0o600, true, fs.ModeDir | 0o700},
+		// repeated and mixed who letters, as GNU chmod: "a" means every class regardless
+		{"uugo+x", 0o644, false, 0o755},
+		{"ugoa=r", 0o777, false, 0o444},
+		{"aa-w", 0o666, false, 0o444},
+		{"ao-w", 0o666, false, 0o444},
+		{"uu=rw,gg=", 0o777, false, 0o607},
+	}
+	for _, c := range C {
+		e, err := compileChmodExpr(c.expr)
+		if err != nil {
+			t.Errorf("with %q, got compile error: %v", c.expr, err)
+			continue
+		}
+		if v := e.apply(c.base, c.isDir); v != c.v {
+			t.Errorf("with %q on %v (dir %v), expected %v. got %v", c.expr, c.base, c.isDir, c.v, v)
+		}
+	}
+}
+
+func TestInvalidChmodExpr(t *testing.T) {
+	C := []string{"", "u", "u+q", "ug", "u+x,", ",u+x", "u+x,,g+x", "x+u", "u=gr"}
+	for _, c := range C {
+		if e, err := compileChmodExpr(c); err == nil {
+			t.Errorf("got nil error for %q, compiled to %+v", c, e)
+		}
+	}
+}
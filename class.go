@@ -0,0 +1,131 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Class identifies one of the three classes of users that POSIX permission bits apply to.
+type Class uint8
+
+const (
+	ClassOwner Class = iota // the user owning the file ("u")
+	ClassGroup              // members of the file's group ("g")
+	ClassOther              // everyone else ("o")
+)
+
+// String returns the name of the class: `owner`, `group`, or `other`.
+func (c Class) String() string {
+	switch c {
+	case ClassOwner:
+		return "owner"
+	case ClassGroup:
+		return "group"
+	case ClassOther:
+		return "other"
+	}
+	return fmt.Sprintf("Class(%d)", uint8(c))
+}
+
+// shift returns the bit offset of the class' permission digit.
+func (c Class) shift() uint {
+	return 3 * uint(2-c)
+}
+
+// Access returns the rights p grants to class c.
+func (p Perm) Access(c Class) Access {
+	return Access(fs.FileMode(p)>>c.shift()) & AccessAll
+}
+
+// Subject identifies a process for access evaluation by its effective user id and the ids of every
+// group it belongs to, primary and supplementary.
+type Subject struct {
+	UID  int
+	GIDs []int
+}
+
+// InGroup reports whether s is a member of group gid.
+func (s Subject) InGroup(gid int) bool {
+	for _, g := range s.GIDs {
+		if g == gid {
+			return true
+		}
+	}
+	return false
+}
+
+// Decision explains the outcome of an access check made by Evaluate.
+type Decision struct {
+	// Allowed is set if every requested right is granted.
+	Allowed bool
+	// Superuser is set if the subject is root, in which case Class is not meaningful.
+	Superuser bool
+	// Class is the single class that was consulted.
+	Class Class
+	// Granted holds the rights of the consulted class (or of root).
+	Granted Access
+	// Missing holds the requested rights that were not granted.
+	Missing Access
+	// Reason is a human readable explanation of the decision.
+	Reason string
+}
+
+// String returns the Reason of the decision.
+func (d Decision) String() string {
+	return d.Reason
+}
+
+// Evaluate decides whether subject s may access a file with mode p, owned by uid owner and gid group,
+// with the rights in want. Like the kernel, it consults exactly one class: the owner class if s owns
+// the file, otherwise the group class if s is a member of the file's group, otherwise the other class.
+// Rights granted to a later class never rescue a denial by an earlier one, which is the usual cause of
+// surprising denials (eg a file mode 0077 is unreadable by its owner). The superuser may read and
+// write anything, and execute anything that is a directory or has any execute bit set. Access control
+// lists and capabilities other than those of root are not considered.
+func Evaluate(p Perm, owner, group int, s Subject, want Access) Decision {
+	want &= AccessAll
+	var d Decision
+	if s.UID == 0 {
+		d.Superuser = true
+		d.Granted = AccessRead | AccessWrite
+		if fs.FileMode(p).IsDir() || fs.FileMode(p)&0o111 != 0 {
+			d.Granted |= AccessExecute
+		}
+		d.Missing = want &^ d.Granted
+		d.Allowed = d.Missing == 0
+		if d.Allowed {
+			d.Reason = fmt.Sprintf("uid 0 is the superuser and bypasses permission checks for %v", want)
+		} else {
+			d.Reason = "uid 0 is the superuser, but cannot execute a file with no execute bits set"
+		}
+		return d
+	}
+
+	var why string
+	switch {
+	case s.UID == owner:
+		d.Class = ClassOwner
+		why = fmt.Sprintf("uid %d owns the file", s.UID)
+	case s.InGroup(group):
+		d.Class = ClassGroup
+		why = fmt.Sprintf("uid %d does not own the file (owner is uid %d) but is a member of its group gid %d", s.UID, owner, group)
+	default:
+		d.Class = ClassOther
+		why = fmt.Sprintf("uid %d neither owns the file (owner is uid %d) nor is a member of its group gid %d", s.UID, owner, group)
+	}
+	d.Granted = p.Access(d.Class)
+	d.Missing = want &^ d.Granted
+	d.Allowed = d.Missing == 0
+	if d.Allowed {
+		d.Reason = fmt.Sprintf("%s, so %s rights %v apply and include %v", why, d.Class, d.Granted, want)
+		return d
+	}
+	d.Reason = fmt.Sprintf("%s, so %s rights %v apply and lack %v", why, d.Class, d.Granted, d.Missing)
+	for c := d.Class + 1; c <= ClassOther; c++ {
+		if p.Access(c).Has(want) {
+			d.Reason += fmt.Sprintf("; %s rights %v would allow it but are not consulted", c, p.Access(c))
+			break
+		}
+	}
+	return d
+}
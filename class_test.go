@@ -0,0 +1,58 @@
+package posixperm
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestPermAccess(t *testing.T) {
+	p := Perm(0o751)
+	if a := p.Access(ClassOwner); a != AccessAll {
+		t.Errorf("expected owner rwx, got %v", a)
+	}
+	if a := p.Access(ClassGroup); a != AccessRead|AccessExecute {
+		t.Errorf("expected group rx, got %v", a)
+	}
+	if a := p.Access(ClassOther); a != AccessExecute {
+		t.Errorf("expected other x, got %v", a)
+	}
+	if a := Perm(fs.ModeSetuid | fs.ModeDir).Access(ClassOther); a != AccessNone {
+		t.Errorf("expected special bits to grant no access, got %v", a)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	const owner, group = 1000, 100
+	C := []struct {
+		p       Perm
+		s       Subject
+		want    Access
+		allowed bool
+		class   Class
+		reason  string
+	}{
+		{0o640, Subject{UID: 1000}, AccessRead | AccessWrite, true, ClassOwner, "owns the file"},
+		{0o640, Subject{UID: 1001, GIDs: []int{100}}, AccessRead, true, ClassGroup, "member of its group"},
+		{0o640, Subject{UID: 1001, GIDs: []int{100}}, AccessWrite, false, ClassGroup, "lack w"},
+		{0o640, Subject{UID: 1001, GIDs: []int{5}}, AccessRead, false, ClassOther, "neither owns"},
+		{0o077, Subject{UID: 1000, GIDs: []int{100}}, AccessRead, false, ClassOwner, "group rights rwx would allow it"},
+		{0o604, Subject{UID: 1001, GIDs: []int{100}}, AccessRead, false, ClassGroup, "other rights r would allow it"},
+		{0o000, Subject{UID: 0}, AccessRead | AccessWrite, true, ClassOwner, "superuser"},
+		{0o644, Subject{UID: 0}, AccessExecute, false, ClassOwner, "cannot execute"},
+		{0o744, Subject{UID: 0}, AccessExecute, true, ClassOwner, "superuser"},
+		{Perm(fs.ModeDir), Subject{UID: 0}, AccessExecute, true, ClassOwner, "superuser"},
+	}
+	for _, c := range C {
+		d := Evaluate(c.p, owner, group, c.s, c.want)
+		if d.Allowed != c.allowed {
+			t.Errorf("with %v and %+v wanting %v, expected allowed=%v: %v", c.p, c.s, c.want, c.allowed, d)
+		}
+		if !d.Superuser && d.Class != c.class {
+			t.Errorf("with %v and %+v, expected class %v, got %v", c.p, c.s, c.class, d.Class)
+		}
+		if !strings.Contains(d.Reason, c.reason) {
+			t.Errorf("with %v and %+v, expected reason to mention %q: %q", c.p, c.s, c.reason, d.Reason)
+		}
+	}
+}
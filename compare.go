@@ -0,0 +1,56 @@
+package posixperm
+
+import "io/fs"
+
+// ClassDiff breaks down the difference between two modes a and b by class and by right, for views
+// showing a grid of changes rather than a single delta. See Compare.
+type ClassDiff struct {
+	// More holds, for each Class, the rights a grants that b does not; Less holds those b grants that
+	// a does not.
+	More, Less [3]Access
+	// MoreSpecial and LessSpecial likewise hold the setuid, setgid, and sticky bits set in only one of
+	// a and b.
+	MoreSpecial, LessSpecial fs.FileMode
+}
+
+// Compare returns the per-class and per-special-bit differences between a and b. File type bits are
+// ignored.
+func Compare(a, b Perm) ClassDiff {
+	var d ClassDiff
+	for c := ClassOwner; c <= ClassOther; c++ {
+		d.More[c] = a.Access(c) &^ b.Access(c)
+		d.Less[c] = b.Access(c) &^ a.Access(c)
+	}
+	d.MoreSpecial = fs.FileMode(a) &^ fs.FileMode(b) & specialBits
+	d.LessSpecial = fs.FileMode(b) &^ fs.FileMode(a) & specialBits
+	return d
+}
+
+// Right reports whether a grants class c more (+1), less (-1), or the same (0) of the single right r
+// (AccessRead, AccessWrite, or AccessExecute) as b.
+func (d ClassDiff) Right(c Class, r Access) int {
+	switch {
+	case d.More[c].Has(r):
+		return 1
+	case d.Less[c].Has(r):
+		return -1
+	}
+	return 0
+}
+
+// Special reports whether a sets the special bit m (fs.ModeSetuid, fs.ModeSetgid, or fs.ModeSticky)
+// and b does not (+1), the reverse (-1), or both agree (0).
+func (d ClassDiff) Special(m fs.FileMode) int {
+	switch {
+	case d.MoreSpecial&m != 0:
+		return 1
+	case d.LessSpecial&m != 0:
+		return -1
+	}
+	return 0
+}
+
+// Equal reports whether a and b grant the same rights and set the same special bits.
+func (d ClassDiff) Equal() bool {
+	return d == ClassDiff{}
+}
@@ -0,0 +1,31 @@
+package posixperm
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	d := Compare(Perm(fs.ModeSetuid|0o751), Perm(fs.ModeSticky|0o644))
+	grid := [3][3]int{
+		{0, 0, 1},  // owner: r same, w same, x more
+		{0, 0, 1},  // group: x more
+		{-1, 0, 1}, // other: r less, x more
+	}
+	for c := ClassOwner; c <= ClassOther; c++ {
+		for i, r := range []Access{AccessRead, AccessWrite, AccessExecute} {
+			if v := d.Right(c, r); v != grid[c][i] {
+				t.Errorf("with %v %v, expected %d. got %d", c, r, grid[c][i], v)
+			}
+		}
+	}
+	if d.Special(fs.ModeSetuid) != 1 || d.Special(fs.ModeSticky) != -1 || d.Special(fs.ModeSetgid) != 0 {
+		t.Errorf("unexpected special bit comparison %+v", d)
+	}
+	if d.Equal() {
+		t.Errorf("expected differing modes not to be equal")
+	}
+	if d := Compare(Perm(fs.ModeDir|0o755), 0o755); !d.Equal() {
+		t.Errorf("expected type bits to be ignored, got %+v", d)
+	}
+}
@@ -0,0 +1,84 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// numeric modes as accepted by Ansible (Python's int(s, 8)) and Puppet respectively
+var (
+	fmtAnsibleOctal = regexp.MustCompile(`^(0o)?0*[0-7]{1,4}$`)
+	fmtPuppetOctal  = regexp.MustCompile(`^[0-7]{1,4}$`)
+)
+
+// compat identifies a configuration management tool whose mode syntax is emulated.
+type compat int
+
+const (
+	compatNone compat = iota
+	compatAnsible
+	compatPuppet
+)
+
+// WithAnsible accepts exactly the string forms that Ansible's `mode:` parameter accepts, instead of
+// the syntaxes this package normally understands: up to 4 octal digits with an optional `0` or `0o`
+// prefix, where special bits use the traditional unix encoding (eg `4755`), or a comma separated
+// chmod(1) symbolic mode (eg `u=rw,g=r,o=r`). A clause with no class letters applies to all classes
+// as if the umask were 0. Ansible applies symbolic modes to the existing mode of each file, so a mode
+// whose result depends on it (eg `u+x` or `a+rX`) is an error; see ApplyExpr for those. Ansible's
+// `preserve` is not a mode and is rejected. Note that Ansible requires octal modes to be quoted in
+// YAML, since a bare 644 is a decimal integer; see WithJSONNumbers for numeric values.
+func WithAnsible() Option {
+	return func(o *options) { o.compat = compatAnsible }
+}
+
+// WithPuppet accepts exactly the string forms that Puppet's `mode =>` attribute accepts: 1 to 4 octal
+// digits without a prefix other than leading zeros, where special bits use the traditional unix
+// encoding, or a comma separated chmod(1) symbolic mode. Symbolic modes are restricted as described
+// for WithAnsible.
+func WithPuppet() Option {
+	return func(o *options) { o.compat = compatPuppet }
+}
+
+// detectCompatFormat returns the syntax that b is recognized as under the rules of tool c.
+func detectCompatFormat(c compat, b []byte) Format {
+	switch {
+	case c == compatAnsible && fmtAnsibleOctal.Match(b):
+		return FormatChmodOctal
+	case c == compatPuppet && fmtPuppetOctal.Match(b):
+		return FormatChmodOctal
+	case fmtChmodSymbolic.Match(b):
+		return FormatChmodSymbolic
+	}
+	return FormatUnknown
+}
+
+// fromChmodOctal parses digits in the traditional unix encoding, where 4000 is setuid, 2000 setgid,
+// and 1000 sticky.
+func (p *Perm) fromChmodOctal(b []byte) error {
+	v, err := strconv.ParseUint(strings.TrimPrefix(string(b), "0o"), 8, 32)
+	if err != nil || v > 0o7777 {
+		return fmt.Errorf("cannot parse octal mode %q", b)
+	}
+	*p = fromUnix(v)
+	return nil
+}
+
+// fromChmodSymbolic evaluates a chmod(1) symbolic mode that sets every permission bit, such as
+// `u=rw,go=r`. A mode whose result depends on the existing mode, such as `u+x`, is an error, since
+// the tools that accept it apply it to each file in turn.
+func (p *Perm) fromChmodSymbolic(b []byte) error {
+	e, err := compileChmodExpr(string(b))
+	if err != nil {
+		return err
+	}
+	r := e.apply(0, false)
+	if e.apply(fs.FileMode(chmodBits), false) != r {
+		return fmt.Errorf("symbolic mode %q depends on the existing mode; apply it with ApplyExpr", b)
+	}
+	*p = Perm(r)
+	return nil
+}
@@ -0,0 +1,63 @@
+package posixperm
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestValidCompat(t *testing.T) {
+	C := []struct {
+		s   string
+		opt Option
+		v   Perm
+	}{
+		{"0644", WithAnsible(), 0o644},
+		{"644", WithAnsible(), 0o644},
+		{"0o644", WithAnsible(), 0o644},
+		{"4755", WithAnsible(), Perm(fs.ModeSetuid | 0o755)},
+		{"01777", WithAnsible(), Perm(fs.ModeSticky | 0o777)},
+		{"u=rw,g=r,o=r", WithAnsible(), 0o644},
+		{"a=,u+rwx,g+rX", WithAnsible(), 0o750},
+		{"0644", WithPuppet(), 0o644},
+		{"2755", WithPuppet(), Perm(fs.ModeSetgid | 0o755)},
+		{"7", WithPuppet(), 0o007},
+		{"ug=rw,o=", WithPuppet(), 0o660},
+	}
+	for _, c := range C {
+		p, err := FromString(c.s, c.opt)
+		if err != nil {
+			t.Errorf("with %q, expected %v. got error: %v", c.s, c.v, err)
+		}
+		if p != c.v {
+			t.Errorf("with %q, expected %v. got %v", c.s, c.v, p)
+		}
+	}
+}
+
+func TestInvalidCompat(t *testing.T) {
+	C := []struct {
+		s   string
+		opt Option
+	}{
+		{"preserve", WithAnsible()},
+		{"rwxr-xr-x", WithAnsible()},
+		{"a=rwx o-w", WithAnsible()},
+		{"0o644", WithPuppet()},
+		{"00644", WithPuppet()},
+		{"17777", WithAnsible()},
+		{"0899", WithAnsible()},
+		{"u=rw;g=r", WithPuppet()},
+		{"u+rwx,g+rX", WithAnsible()},
+		{"u=rw,g=r", WithPuppet()},
+		{"a+rX", WithAnsible()},
+	}
+	for _, c := range C {
+		p, err := FromString(c.s, c.opt)
+		if err == nil {
+			t.Errorf("got nil error for %q, parsed to %v", c.s, p)
+		}
+	}
+	if _, err := FromString("644", WithAnsible(), WithStrictOctal()); err == nil {
+		t.Errorf("expected WithStrictOctal to apply in compatibility mode")
+	}
+}
@@ -0,0 +1,118 @@
+package posixperm
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// CopyModeOption configures CopyMode.
+type CopyModeOption func(*copyModeOptions)
+
+type copyModeOptions struct {
+	permOnly bool
+	dryRun   bool
+}
+
+// CopyPermOnly makes CopyMode copy only the 9 permission bits, leaving the setuid, setgid, and
+// sticky bits of the destination as they are.
+func CopyPermOnly() CopyModeOption {
+	return func(o *copyModeOptions) { o.permOnly = true }
+}
+
+// CopyDryRun makes CopyMode compute the change it would make without making it.
+func CopyDryRun() CopyModeOption {
+	return func(o *copyModeOptions) { o.dryRun = true }
+}
+
+// CopyMode reads the mode of src and applies it to dst, like `chmod --reference=src dst`. Symbolic
+// links are followed. The result describes the change to dst, as for ApplyExpr.
+//
+// If src and dst are on different devices, the destination filesystem may not store modes faithfully
+// (eg vfat, or some network filesystems), so CopyMode reads dst back after changing it and returns
+// an error if its mode does not match.
+func CopyMode(src, dst string, opts ...CopyModeOption) (ApplyResult, error) {
+	var o copyModeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	r := ApplyResult{Path: dst, DryRun: o.dryRun}
+	sfi, err := os.Stat(src)
+	if err != nil {
+		r.Err = err
+		return r, err
+	}
+	dfi, err := os.Stat(dst)
+	if err != nil {
+		r.Err = err
+		return r, err
+	}
+	bits := chmodBits
+	if o.permOnly {
+		bits = fs.ModePerm
+	}
+	before := dfi.Mode()
+	after := before&^bits | sfi.Mode()&bits
+	r.Before, r.After, r.Changed = Perm(before), Perm(after), after != before
+	if !r.Changed || o.dryRun {
+		return r, nil
+	}
+	r.Time = time.Now()
+	if err := os.Chmod(dst, after&chmodBits); err != nil {
+		r.Err = err
+		return r, err
+	}
+	if sdev, ok := fileDevice(sfi); ok {
+		if ddev, _ := fileDevice(dfi); ddev != sdev {
+			r.Err = verifyMode(dst, after)
+		}
+	}
+	return r, r.Err
+}
+
+// verifyMode returns an error unless the file named path has the permission bits of want.
+func verifyMode(path string, want fs.FileMode) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if got := fi.Mode() & chmodBits; got != want&chmodBits {
+		return fmt.Errorf("%s: filesystem stored mode %v instead of %v", path, got, want&chmodBits)
+	}
+	return nil
+}
+
+// CopyFilePreserving copies the contents of the regular file src to dst, creating or truncating it,
+// and then gives dst the mode of src and, unless owner is zero, the ownership owner, as FileSpec.Apply
+// would. While its contents are written, dst is accessible only to its owner, so that a file copied
+// with a restrictive mode is never briefly readable by others.
+func CopyFilePreserving(src, dst string, owner Ownership) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("%s: not a regular file", src)
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	if err = out.Chmod(0o600); err == nil {
+		_, err = io.Copy(out, in)
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return FileSpec{Mode: Perm(fi.Mode() & chmodBits), Owner: owner}.Apply(dst)
+}
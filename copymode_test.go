@@ -0,0 +1,69 @@
+package posixperm
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestCopyMode(t *testing.T) {
+	C := []struct {
+		src, dst fs.FileMode
+		opts     []CopyModeOption
+		want     fs.FileMode
+	}{
+		{0o640, 0o600, nil, 0o640},
+		{0o755 | fs.ModeSetuid, 0o644, nil, 0o755 | fs.ModeSetuid},
+		{0o755 | fs.ModeSetuid, 0o644, []CopyModeOption{CopyPermOnly()}, 0o755},
+		{0o700, 0o600 | fs.ModeSetgid, []CopyModeOption{CopyPermOnly()}, 0o700 | fs.ModeSetgid},
+		{0o640, 0o600, []CopyModeOption{CopyDryRun()}, 0o600},
+		{0o600, 0o600, nil, 0o600},
+	}
+	for _, c := range C {
+		root := makeTree(t, map[string]fs.FileMode{"src": c.src, "dst": c.dst})
+		src, dst := filepath.Join(root, "src"), filepath.Join(root, "dst")
+		r, err := CopyMode(src, dst, c.opts...)
+		if err != nil {
+			t.Errorf("with %v to %v, got error: %v", c.src, c.dst, err)
+			continue
+		}
+		if got := modeOf(t, dst); got != c.want {
+			t.Errorf("with %v to %v, expected %v. got %v", c.src, c.dst, c.want, got)
+		}
+		if r.Changed != (r.Before != r.After) || r.Before != Perm(c.dst) {
+			t.Errorf("with %v to %v, got inconsistent result %+v", c.src, c.dst, r)
+		}
+	}
+	root := makeTree(t, map[string]fs.FileMode{"dst": 0o600})
+	if _, err := CopyMode(filepath.Join(root, "missing"), filepath.Join(root, "dst")); err == nil {
+		t.Errorf("expected error copying from a missing file")
+	}
+}
+
+func TestCopyFilePreserving(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "src"), filepath.Join(dir, "dst")
+	if err := os.WriteFile(src, []byte("contents"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(src, 0o750|fs.ModeSetgid); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dst, []byte("old and longer"), 0o666); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyFilePreserving(src, dst, Ownership{User: strconv.Itoa(os.Getuid())}); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if b, err := os.ReadFile(dst); err != nil || string(b) != "contents" {
+		t.Errorf("expected copied contents, got %q, %v", b, err)
+	}
+	if got, want := modeOf(t, dst), 0o750|fs.ModeSetgid; got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if err := CopyFilePreserving(dir, filepath.Join(dir, "d"), Ownership{}); err == nil {
+		t.Errorf("expected error copying a directory")
+	}
+}
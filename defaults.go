@@ -0,0 +1,11 @@
+package posixperm
+
+// Widely used modes, so that application code can name its intent rather than repeat octal values.
+// They carry permission bits only; use Dir to add the directory type bit where needed.
+const (
+	DefaultFile Perm = 0o644 // rw-r--r--, owner may write and everyone may read
+	DefaultDir  Perm = 0o755 // rwxr-xr-x, owner may write and everyone may list and enter
+	PrivateFile Perm = 0o600 // rw-------, only the owner may read or write
+	PrivateDir  Perm = 0o700 // rwx------, only the owner may list, enter, or modify
+	Secret      Perm = 0o400 // r--------, only the owner may read, and nobody may write
+)
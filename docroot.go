@@ -0,0 +1,38 @@
+package posixperm
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+)
+
+// the conventional web content layout: directories 0755, files 0644, or 0755 if already executable
+const docrootExpr = "u=rwX,go=rX"
+
+// HardenDocroot applies the conventional layout for served web content to the tree rooted at root:
+// directories 0755 and files 0644 (or 0755 for files that are already executable, such as CGI
+// scripts), so that nothing is writable except by its owner, and setuid and setgid are cleared from
+// files. It is ApplyExpr with the expression `u=rwX,go=rX`, and accepts the same options; use DryRun
+// to compute the changes without making them.
+//
+// Upload directories, which the server must be able to write to, are skipped along with their
+// contents. They are given as slash separated paths relative to root, eg "wp-content/uploads".
+func HardenDocroot(root string, uploads []string, opts ...ApplyOption) ([]ApplyResult, error) {
+	return HardenDocrootContext(context.Background(), root, uploads, opts...)
+}
+
+// HardenDocrootContext is like HardenDocroot, but stops promptly once ctx is done; see
+// ApplyExprContext.
+func HardenDocrootContext(ctx context.Context, root string, uploads []string, opts ...ApplyOption) ([]ApplyResult, error) {
+	skip := make(map[string]bool, len(uploads))
+	for _, u := range uploads {
+		skip[filepath.Join(root, filepath.FromSlash(u))] = true
+	}
+	opts = append(opts, func(o *applyOptions) {
+		prev := o.skip
+		o.skip = func(path string, d fs.DirEntry) bool {
+			return skip[path] || prev != nil && prev(path, d)
+		}
+	})
+	return ApplyExprContext(ctx, root, docrootExpr, opts...)
+}
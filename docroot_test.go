@@ -0,0 +1,49 @@
+package posixperm
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHardenDocroot(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{
+		"index.html":          0o666,
+		"cgi-bin/":            0o777,
+		"cgi-bin/form.cgi":    0o775,
+		"assets/":             0o700,
+		"assets/app.js":       0o600,
+		"assets/logo.png":     fs.ModeSetgid | 0o640,
+		"uploads/":            0o777,
+		"uploads/photo.jpg":   0o666,
+		"cache/":              0o770,
+		"cache/page.html.tmp": 0o660,
+	})
+	skipCache := SkipPaths(func(path string, d fs.DirEntry) bool { return strings.HasSuffix(path, "cache") })
+	results, err := HardenDocroot(root, []string{"uploads"}, skipCache)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if len(results) != 7 {
+		t.Errorf("expected 7 results, got %d: %+v", len(results), results)
+	}
+	C := map[string]fs.FileMode{
+		"":                    0o755,
+		"index.html":          0o644,
+		"cgi-bin":             0o755,
+		"cgi-bin/form.cgi":    0o755,
+		"assets":              0o755,
+		"assets/app.js":       0o644,
+		"assets/logo.png":     0o644,
+		"uploads":             0o777,
+		"uploads/photo.jpg":   0o666,
+		"cache":               0o770,
+		"cache/page.html.tmp": 0o660,
+	}
+	for name, want := range C {
+		if got := modeOf(t, filepath.Join(root, name)); got != want {
+			t.Errorf("with %q, expected %v. got %v", name, want, got)
+		}
+	}
+}
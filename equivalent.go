@@ -0,0 +1,69 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Equivalent reports whether the permission expressions a and b, in any syntax accepted by
+// FromString, denote the same mode. For example `0o755`, `rwxr-xr-x`, and `a=rx u+w` are all
+// equivalent. An error is returned if either expression cannot be parsed.
+func Equivalent(a, b string) (bool, error) {
+	pa, err := FromString(a)
+	if err != nil {
+		return false, err
+	}
+	pb, err := FromString(b)
+	if err != nil {
+		return false, err
+	}
+	return pa == pb, nil
+}
+
+// chmodFunc compiles a chmod(1) mode operand into a function of the existing mode.
+func chmodFunc(s string) (func(m fs.FileMode, isDir bool) fs.FileMode, error) {
+	if fmtChmodOctal.MatchString(s) {
+		p, err := parseChmodMode(s)
+		if err != nil {
+			return nil, err
+		}
+		// like GNU chmod, numeric modes only clear the setuid and setgid bits of a directory if
+		// given with at least 5 digits
+		keep := len(s) < 5
+		return func(m fs.FileMode, isDir bool) fs.FileMode {
+			clear := createFileBits
+			if isDir && keep {
+				clear &^= fs.ModeSetuid | fs.ModeSetgid
+			}
+			return m&^clear | fs.FileMode(p)
+		}, nil
+	}
+	e, err := compileChmodExpr(s)
+	if err != nil {
+		return nil, fmt.Errorf("unrecognized chmod mode %q", s)
+	}
+	return e.apply, nil
+}
+
+// EquivalentChmod reports whether a and b, each a chmod(1) mode operand such as `0755`, `u+x,go-w`,
+// or `a=rX`, have the same effect on every file and directory whatever its existing mode. Unlike
+// Equivalent, symbolic modes here are relative to the existing mode, as they are for chmod, so `u+x`
+// and `0100` are not equivalent. This lets tooling tell that a proposed change is a no-op (eg
+// `go=u-w` and `g=u-w,o=u-w`).
+func EquivalentChmod(a, b string) (bool, error) {
+	fa, err := chmodFunc(a)
+	if err != nil {
+		return false, err
+	}
+	fb, err := chmodFunc(b)
+	if err != nil {
+		return false, err
+	}
+	for v := uint64(0); v <= 0o7777; v++ {
+		m := fs.FileMode(fromUnix(v))
+		if fa(m, false) != fb(m, false) || fa(m|fs.ModeDir, true) != fb(m|fs.ModeDir, true) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,63 @@
+package posixperm
+
+import "testing"
+
+func TestEquivalent(t *testing.T) {
+	C := []struct {
+		a, b string
+		v    bool
+	}{
+		{"0755", "rwxr-xr-x", true},
+		{"0o755", "a=rx u+w", true},
+		{"755", "-rwxr-xr-x", true},
+		{"rwx", "0777", true},
+		{"0644", "0640", false},
+		{"u=rw g=r o=r", "a=r u+w", true},
+		{"drwxr-xr-x", "0755", false},
+	}
+	for _, c := range C {
+		v, err := Equivalent(c.a, c.b)
+		if err != nil {
+			t.Errorf("with %q and %q, got error: %v", c.a, c.b, err)
+		}
+		if v != c.v {
+			t.Errorf("with %q and %q, expected %v. got %v", c.a, c.b, c.v, v)
+		}
+	}
+	if _, err := Equivalent("0755", "bogus"); err == nil {
+		t.Errorf("expected error comparing unparseable expression")
+	}
+}
+
+func TestEquivalentChmod(t *testing.T) {
+	C := []struct {
+		a, b string
+		v    bool
+	}{
+		{"go=u-w", "g=u-w,o=u-w", true},
+		{"u+x,u-x", "u-x", true},
+		{"a+rwx", "ugo+rwx", true},
+		{"+rwx", "a+rwx", true},
+		{"0755", "u=rwx,go=rx", true},
+		{"755", "0755", true},
+		{"u+x", "0100", false},
+		{"a+X", "a+x", false},
+		{"u=rwx,go=rx", "a=rx,u+w", true},
+		{"g+s", "g+s,o+s", true},
+		{"u+t", "o+s", true},
+		{"0755", "00755", false},
+		{"4755", "0755", false},
+	}
+	for _, c := range C {
+		v, err := EquivalentChmod(c.a, c.b)
+		if err != nil {
+			t.Errorf("with %q and %q, got error: %v", c.a, c.b, err)
+		}
+		if v != c.v {
+			t.Errorf("with %q and %q, expected %v. got %v", c.a, c.b, c.v, v)
+		}
+	}
+	if _, err := EquivalentChmod("u+x", "rwxr-xr-x"); err == nil {
+		t.Errorf("expected error comparing non-chmod mode")
+	}
+}
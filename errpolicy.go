@@ -0,0 +1,95 @@
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// ErrorPolicy selects how a tree operation responds to errors affecting individual paths, such as
+// permission denied, which tools managing permissions run into constantly.
+type ErrorPolicy int
+
+const (
+	// FailFast stops at the first error. It is the default for Stats and AuditHomes.
+	FailFast ErrorPolicy = iota + 1
+	// CollectErrors records every error and continues with the next path. It is the default for
+	// ApplyExpr.
+	CollectErrors
+	// SkipUnreadable skips paths that cannot be read for lack of permission, listing them in the
+	// TreeError, and stops at any other error.
+	SkipUnreadable
+)
+
+// WithErrorPolicy sets the ErrorPolicy of ApplyExpr.
+func WithErrorPolicy(p ErrorPolicy) ApplyOption {
+	return func(o *applyOptions) { o.errs.policy = p }
+}
+
+// ScanErrorPolicy sets the ErrorPolicy of Stats or AuditHomes.
+func ScanErrorPolicy(p ErrorPolicy) ScanOption {
+	return func(o *scanOptions) { o.errs.policy = p }
+}
+
+// TreeError aggregates the problems a tree operation ran into. It is returned whenever a walk
+// skipped any path or hit any error, along with the partial results.
+type TreeError struct {
+	// Skipped lists the paths passed over by SkipUnreadable.
+	Skipped []string
+	// Errs holds every other error, in the order they occurred.
+	Errs []error
+}
+
+// Error implements the error interface, summarizing the skipped paths and errors.
+func (e *TreeError) Error() string {
+	var parts []string
+	if len(e.Skipped) > 0 {
+		parts = append(parts, fmt.Sprintf("skipped %d unreadable paths: %s", len(e.Skipped), strings.Join(e.Skipped, ", ")))
+	}
+	if len(e.Errs) > 0 {
+		parts = append(parts, errors.Join(e.Errs...).Error())
+	}
+	return strings.Join(parts, "\n")
+}
+
+// Unwrap returns Errs, for use with errors.Is and errors.As. Skipped paths are not errors.
+func (e *TreeError) Unwrap() []error {
+	return e.Errs
+}
+
+// errStopWalk stops a walk once its ErrorPolicy says so; the cause has already been recorded.
+var errStopWalk = errors.New("walk stopped")
+
+// treeErrors applies an ErrorPolicy, accumulating a TreeError.
+type treeErrors struct {
+	policy ErrorPolicy
+	TreeError
+}
+
+// add records err for path, where reading is set if the path could not be read (as opposed to
+// changed). It returns errStopWalk if the walk must stop, or nil to continue.
+func (c *treeErrors) add(path string, err error, reading bool) error {
+	if c.policy == SkipUnreadable && reading && errors.Is(err, fs.ErrPermission) {
+		c.Skipped = append(c.Skipped, path)
+		return nil
+	}
+	c.Errs = append(c.Errs, err)
+	if c.policy == CollectErrors {
+		return nil
+	}
+	return errStopWalk
+}
+
+// result returns the accumulated errors, including walkErr as returned by the walk, as a
+// *TreeError, or nil if there were none.
+func (c *treeErrors) result(walkErr error) error {
+	if walkErr != nil && walkErr != errStopWalk {
+		c.Errs = append(c.Errs, walkErr)
+	}
+	if len(c.Skipped) == 0 && len(c.Errs) == 0 {
+		return nil
+	}
+	e := c.TreeError
+	return &e
+}
@@ -0,0 +1,76 @@
+package posixperm
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+// deniedFS fails to open the paths in deny with the associated error.
+type deniedFS struct {
+	fsys fs.FS
+	deny map[string]error
+}
+
+func (d deniedFS) Open(name string) (fs.File, error) {
+	if err, ok := d.deny[name]; ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	return d.fsys.Open(name)
+}
+
+func TestErrorPolicy(t *testing.T) {
+	errBroken := errors.New("broken")
+	fsys := deniedFS{
+		fsys: fstest.MapFS{
+			"a/x":    {Mode: 0o644},
+			"b/x":    {Mode: 0o644},
+			"c/x":    {Mode: 0o644},
+			"d/x":    {Mode: 0o644},
+			"e.conf": {Mode: 0o600},
+		},
+		deny: map[string]error{"b": fs.ErrPermission, "d": fs.ErrPermission},
+	}
+	broken := deniedFS{fsys: fsys, deny: map[string]error{"c": errBroken}}
+	C := []struct {
+		fsys    fs.FS
+		policy  ErrorPolicy
+		files   int // entries with mode 0644
+		skipped []string
+		errs    int
+	}{
+		{fsys, 0, 1, nil, 1},
+		{fsys, FailFast, 1, nil, 1},
+		{fsys, CollectErrors, 2, nil, 2},
+		{fsys, SkipUnreadable, 2, []string{"b", "d"}, 0},
+		{broken, SkipUnreadable, 1, []string{"b"}, 1},
+		{broken, CollectErrors, 1, nil, 3},
+	}
+	for _, c := range C {
+		s, err := Stats(c.fsys, ScanErrorPolicy(c.policy))
+		var te *TreeError
+		if !errors.As(err, &te) {
+			t.Errorf("with policy %d, expected *TreeError, got %v", c.policy, err)
+			continue
+		}
+		if s.Modes[0o644] != c.files || !reflect.DeepEqual(te.Skipped, c.skipped) || len(te.Errs) != c.errs {
+			t.Errorf("with policy %d, expected %d files, skipped %q and %d errors, got %d, %q and %d (%v)",
+				c.policy, c.files, c.skipped, c.errs, s.Modes[0o644], te.Skipped, len(te.Errs), err)
+		}
+	}
+	if _, err := Stats(fsys, ScanErrorPolicy(CollectErrors)); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("expected collected errors to unwrap to fs.ErrPermission, got %v", err)
+	}
+	if _, err := Stats(fstest.MapFS{"a": {Mode: 0o644}}, ScanErrorPolicy(FailFast)); err != nil {
+		t.Errorf("expected no error for a readable tree, got %v", err)
+	}
+}
+
+func TestTreeErrorString(t *testing.T) {
+	e := &TreeError{Skipped: []string{"a", "b"}, Errs: []error{errors.New("c failed")}}
+	if got, want := e.Error(), "skipped 2 unreadable paths: a, b\nc failed"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
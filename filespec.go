@@ -0,0 +1,251 @@
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// the permission bits honored by chmod, as opposed to file type bits
+const chmodBits = fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+
+// FileSpec combines a Perm and an Ownership with an optional expected file type, the triple that
+// deployment manifests and packaging scripts typically express for each path they manage.
+type FileSpec struct {
+	Mode  Perm
+	Owner Ownership
+	// Type holds the expected fs.FileMode type bits (eg fs.ModeDir), or 0 if the type is not
+	// checked. A regular file cannot be distinguished from an unchecked type.
+	Type fs.FileMode
+}
+
+// ParseFileSpec parses s as a FileSpec. Two forms are understood:
+//
+//	`root:root 0644` -- an optional chown-style owner followed by any Perm syntax
+//	`-m 0755 -o app -g app` -- install(1) style flags, where -d marks a directory
+//
+// The install form accepts the options of ParseInstallCommand, including long forms such as
+// `--mode=0644` and attached values such as `-m0644`, but no paths. The mode is interpreted as
+// install(1) does: it defaults to 0755, numeric modes encode special bits the traditional way (eg
+// `4755`), and symbolic clauses may be separated by commas. If the parsed mode carries file type bits (eg `drwxr-xr-x`), they are moved into Type.
+func ParseFileSpec(s string) (f FileSpec, err error) {
+	err = f.UnmarshalText([]byte(s))
+	return
+}
+
+// ExpandFileSpec is like ParseFileSpec, but first replaces ${NAME} and $NAME references in s with
+// the values in vars, or if vars is nil, with environment variables, so that one manifest line such as
+// `${APP_USER}:${APP_GROUP} 0640` can serve several environments. Names consist of letters, digits,
+// and underscores, and do not start with a digit. A reference to an undefined variable is an error,
+// as is a malformed one (eg an unterminated `${` or a `$` not followed by a name), rather than
+// silently producing a different specification.
+func ExpandFileSpec(s string, vars map[string]string) (FileSpec, error) {
+	lookup := os.LookupEnv
+	if vars != nil {
+		lookup = func(k string) (string, bool) { v, ok := vars[k]; return v, ok }
+	}
+	var b strings.Builder
+	var undefined []string
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' {
+			b.WriteByte(s[i])
+			continue
+		}
+		var name string
+		if i+1 < len(s) && s[i+1] == '{' {
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return FileSpec{}, fmt.Errorf("file specification %q has an unterminated ${", s)
+			}
+			name = s[i+2 : i+2+end]
+			if !isVarName(name) {
+				return FileSpec{}, fmt.Errorf("file specification %q has invalid variable name %q", s, name)
+			}
+			i += 2 + end
+		} else {
+			n := 1
+			for i+n < len(s) && isVarName(s[i+1:i+n+1]) {
+				n++
+			}
+			if n == 1 {
+				return FileSpec{}, fmt.Errorf("file specification %q has a $ not followed by a variable name", s)
+			}
+			name = s[i+1 : i+n]
+			i += n - 1
+		}
+		v, ok := lookup(name)
+		if !ok {
+			undefined = append(undefined, name)
+		}
+		b.WriteString(v)
+	}
+	if len(undefined) > 0 {
+		return FileSpec{}, fmt.Errorf("file specification %q refers to undefined variables %s", s, strings.Join(undefined, ", "))
+	}
+	return ParseFileSpec(b.String())
+}
+
+// isVarName reports whether s is a valid variable name for ExpandFileSpec.
+func isVarName(s string) bool {
+	for i, c := range s {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && '0' <= c && c <= '9':
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for this type, following the rules of
+// ParseFileSpec.
+func (f *FileSpec) UnmarshalText(b []byte) error {
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return errors.New("empty file specification")
+	}
+	var r FileSpec
+	var err error
+	if isInstallFlag(fields[0]) {
+		r, err = fileSpecFromInstallArgs(fields)
+	} else {
+		r, err = fileSpecFromFields(fields)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot parse file specification %q: %w", b, err)
+	}
+	if t := fs.FileMode(r.Mode).Type(); t != 0 {
+		if r.Type != 0 && r.Type != t {
+			return fmt.Errorf("file specification %q has conflicting file types", b)
+		}
+		r.Type = t
+		r.Mode = Perm(fs.FileMode(r.Mode) &^ fs.ModeType)
+	}
+	*f = r
+	return nil
+}
+
+// isInstallFlag reports whether s starts the install form of a file specification: a word beginning
+// with a dash that is not itself a mode, such as the `-rw-r--r--` of `ls -l`.
+func isInstallFlag(s string) bool {
+	if !strings.HasPrefix(s, "-") {
+		return false
+	}
+	if len(s) == 2 {
+		return true
+	}
+	_, err := FromString(s)
+	return err != nil
+}
+
+func fileSpecFromFields(fields []string) (r FileSpec, err error) {
+	// symbolic modes may themselves contain spaces, so only split off an owner if the
+	// specification is not a mode on its own
+	if r.Mode, err = FromString(strings.Join(fields, " ")); err == nil || len(fields) == 1 {
+		return
+	}
+	if r.Owner, err = ParseOwnership(fields[0]); err != nil {
+		return
+	}
+	r.Mode, err = FromString(strings.Join(fields[1:], " "))
+	return
+}
+
+func fileSpecFromInstallArgs(args []string) (FileSpec, error) {
+	o, err := parseInstallOptions(args)
+	if err != nil {
+		return FileSpec{}, err
+	}
+	if len(o.operands) > 0 || o.targetDir != "" {
+		return FileSpec{}, errors.New("install options in a file specification cannot name paths")
+	}
+	return o.spec, nil
+}
+
+// MarshalText implements encoding.TextMarshaler for this type. It returns the String() representation.
+func (f FileSpec) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// String returns the owner (if any) and the fs.FileMode string representation of the mode and type,
+// separated by a space, eg `root:root drwxr-xr-x`.
+func (f FileSpec) String() string {
+	mode := (fs.FileMode(f.Mode) | f.Type).String()
+	if f.Owner == (Ownership{}) {
+		return mode
+	}
+	return f.Owner.String() + " " + mode
+}
+
+// Apply changes the ownership (if specified) and then the mode of the named file to match f. The
+// ownership is changed first because chown may clear setuid and setgid bits. If Type is set, Apply
+// refuses to modify a file of a different type.
+func (f FileSpec) Apply(path string) error {
+	return f.apply(hostPaths{}, path)
+}
+
+func (f FileSpec) apply(m mutator, path string) error {
+	if f.Type != 0 {
+		fi, err := m.Lstat(path)
+		if err != nil {
+			return err
+		}
+		if fi.Mode().Type() != f.Type {
+			return fmt.Errorf("%s: expected file type %v, found %v", path, f.Type, fi.Mode().Type())
+		}
+	}
+	if f.Owner != (Ownership{}) {
+		if err := f.Owner.apply(m, path); err != nil {
+			return err
+		}
+	}
+	return m.Chmod(path, fs.FileMode(f.Mode)&chmodBits)
+}
+
+// Verify checks the named file against f, returning nil if it matches or an error describing each
+// mismatch otherwise. Symbolic links are followed unless Type is fs.ModeSymlink.
+func (f FileSpec) Verify(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if f.Type != fs.ModeSymlink && fi.Mode().Type() == fs.ModeSymlink {
+		if fi, err = os.Stat(path); err != nil {
+			return err
+		}
+	}
+	var errs []error
+	if f.Type != 0 && fi.Mode().Type() != f.Type {
+		errs = append(errs, fmt.Errorf("%s: expected file type %v, found %v", path, f.Type, fi.Mode().Type()))
+	}
+	if want, got := fs.FileMode(f.Mode)&chmodBits, fi.Mode()&chmodBits; want != got {
+		errs = append(errs, fmt.Errorf("%s: expected mode %v, found %v", path, want, got))
+	}
+	if f.Owner != (Ownership{}) {
+		if err := f.verifyOwner(path, fi); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f FileSpec) verifyOwner(path string, fi fs.FileInfo) error {
+	uid, gid, err := f.Owner.Resolve()
+	if err != nil {
+		return err
+	}
+	fuid, fgid, ok := fileOwner(fi)
+	if !ok {
+		return fmt.Errorf("%s: file ownership is not available on this platform", path)
+	}
+	if uid != -1 && uid != fuid {
+		return fmt.Errorf("%s: expected owner uid %d, found %d", path, uid, fuid)
+	}
+	if gid != -1 && gid != fgid {
+		return fmt.Errorf("%s: expected group gid %d, found %d", path, gid, fgid)
+	}
+	return nil
+}
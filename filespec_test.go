@@ -0,0 +1,152 @@
+package posixperm
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestValidFileSpec(t *testing.T) {
+	C := []struct {
+		s string
+		v FileSpec
+	}{
+		{"0644", FileSpec{Mode: 0o644}},
+		{"root:root 0644", FileSpec{Mode: 0o644, Owner: Ownership{User: "root", Group: "root"}}},
+		{"app a=rx u+w", FileSpec{Mode: 0o755, Owner: Ownership{User: "app"}}},
+		{"0:0 drwxr-x---", FileSpec{Mode: 0o750, Owner: Ownership{User: "0", Group: "0"}, Type: fs.ModeDir}},
+		{"-m 0600 -o app -g app", FileSpec{Mode: 0o600, Owner: Ownership{User: "app", Group: "app"}}},
+		{"-o app", FileSpec{Mode: 0o755, Owner: Ownership{User: "app"}}},
+		{"-d -m 0750 -g staff", FileSpec{Mode: 0o750, Owner: Ownership{Group: "staff"}, Type: fs.ModeDir}},
+		{"-m 4755", FileSpec{Mode: Perm(fs.ModeSetuid | 0o755)}},
+		{"-m u=rwx,go=rx", FileSpec{Mode: 0o755}},
+		{"--mode=0644 --owner app", FileSpec{Mode: 0o644, Owner: Ownership{User: "app"}}},
+		{"-m0640 -gstaff", FileSpec{Mode: 0o640, Owner: Ownership{Group: "staff"}}},
+		{"-dm 0700 --", FileSpec{Mode: 0o700, Type: fs.ModeDir}},
+	}
+	for _, c := range C {
+		f, err := ParseFileSpec(c.s)
+		if err != nil {
+			t.Errorf("with %q, expected %+v. got error: %v", c.s, c.v, err)
+		}
+		if f != c.v {
+			t.Errorf("with %q, expected %+v. got %+v", c.s, c.v, f)
+		}
+	}
+}
+
+func TestInvalidFileSpec(t *testing.T) {
+	C := []string{
+		"",
+		"root root 0644",
+		"root:root 0999",
+		"-m",
+		"-m 0644 -x",
+		"-d -m rw-r--r--",
+		"-d -m Srw-r--r--",
+		"-m 0644 /etc/app.conf",
+		"-t /srv -m 0644",
+		"--mode",
+	}
+	for _, c := range C {
+		f, err := ParseFileSpec(c)
+		if err == nil {
+			t.Errorf("got nil error for %q, parsed to %+v", c, f)
+		}
+	}
+}
+
+func TestFileSpecRoundTrip(t *testing.T) {
+	C := []string{
+		"-rw-r--r--",
+		"root:wheel drwxr-xr-x",
+		"app: urwxr-x---",
+	}
+	for _, c := range C {
+		f, err := ParseFileSpec(c)
+		if err != nil {
+			t.Errorf("with %q, got parse error: %v", c, err)
+		}
+		if f.String() != c {
+			t.Errorf("with %q, had intermediate %+v, but got %q", c, f, f.String())
+		}
+	}
+}
+
+func TestFileSpecApplyVerify(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "f")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f := FileSpec{Mode: 0o600}
+	if err := f.Verify(path); err == nil {
+		t.Errorf("expected mismatch verifying %v against a 0644 file", f)
+	}
+	if err := f.Apply(path); err != nil {
+		t.Fatalf("got error applying %v: %v", f, err)
+	}
+	if err := f.Verify(path); err != nil {
+		t.Errorf("got error verifying %v after applying it: %v", f, err)
+	}
+	if err := (FileSpec{Mode: 0o700, Type: fs.ModeDir}).Apply(path); err == nil {
+		t.Errorf("expected type mismatch applying a directory spec to a file")
+	}
+	if err := (FileSpec{Mode: 0o700, Type: fs.ModeDir}).Apply(dir); err != nil {
+		t.Errorf("got error applying directory spec to temporary directory: %v", err)
+	}
+	if err := (FileSpec{Mode: 0o700, Type: fs.ModeDir}).Verify(dir); err != nil {
+		t.Errorf("got error verifying temporary directory: %v", err)
+	}
+	if _, _, ok := fileOwner(mustStat(t, path)); ok {
+		f.Owner = Ownership{User: strconv.Itoa(os.Getuid())}
+		if err := f.Verify(path); err != nil {
+			t.Errorf("got error verifying own file ownership: %v", err)
+		}
+	}
+}
+
+func mustStat(t *testing.T, path string) fs.FileInfo {
+	t.Helper()
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi
+}
+
+func TestExpandFileSpec(t *testing.T) {
+	vars := map[string]string{"APP_USER": "app", "APP_GROUP": "www", "MODE": "0640"}
+	C := []struct {
+		in   string
+		want string
+	}{
+		{"${APP_USER}:${APP_GROUP} 0640", "app:www -rw-r-----"},
+		{"$APP_USER: $MODE", "app: -rw-r-----"},
+		{"-m ${MODE} -o ${APP_USER} -d", "app drw-r-----"},
+		{"0644", "-rw-r--r--"},
+		{"$APP_USER:$APP_GROUP $MODE", "app:www -rw-r-----"},
+	}
+	for _, c := range C {
+		f, err := ExpandFileSpec(c.in, vars)
+		if err != nil {
+			t.Errorf("with %q, got error: %v", c.in, err)
+			continue
+		}
+		if f.String() != c.want {
+			t.Errorf("with %q, expected %q, got %q", c.in, c.want, f.String())
+		}
+	}
+	for _, c := range []string{"${NOPE}:root 0644", "root:root $MODE$NOPE", "${APP_USER 0640", "root:${} 0640",
+		"root:$ 0640", "$1 0640", "${APP-USER} 0640", "${9X} 0640"} {
+		if _, err := ExpandFileSpec(c, vars); err == nil {
+			t.Errorf("with %q, expected undefined variable error", c)
+		}
+	}
+	t.Setenv("POSIXPERM_TEST_OWNER", "nobody")
+	if f, err := ExpandFileSpec("${POSIXPERM_TEST_OWNER} 0600", nil); err != nil || f.Owner.User != "nobody" {
+		t.Errorf("expected owner from environment, got %+v, %v", f, err)
+	}
+}
@@ -0,0 +1,168 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// Format identifies one of the syntaxes a permission expression may be written in. It names the
+// syntax detected when parsing (see DetectFormat) and the style to render in (see FormatAs), and
+// marshals to and from its name (eg "explicit-octal") so configuration files and command line flags
+// can refer to styles symbolically.
+type Format int
+
+const (
+	FormatUnknown        Format = iota // not a recognized syntax
+	FormatImplicitOctal                // `644`
+	FormatExplicitOctal                // `0644` or `0o644`; rendered as `0644`
+	FormatBasicSingle                  // `r-x`, the same rights for every class
+	FormatBasicTriple                  // `rwxr-xr-x`
+	FormatSymbolic                     // `a=rx u+w`
+	FormatFull                         // `drwxr-xr-x`, as returned by fs.FileMode's String()
+	FormatShortOctal                   // `7` or `75`, only accepted with WithLenient
+	FormatChmodOctal                   // `4755`, special bits in the traditional unix encoding
+	FormatChmodSymbolic                // `u=rwx,go=rx`, as accepted by chmod(1)
+	FormatClassShorthand               // `u:rw g:r o:-`, one class per token
+	formatCount                        // not a format; the number of formats above
+)
+
+var formatNames = [formatCount]string{
+	FormatUnknown:        "unknown",
+	FormatImplicitOctal:  "implicit-octal",
+	FormatExplicitOctal:  "explicit-octal",
+	FormatBasicSingle:    "basic-single",
+	FormatBasicTriple:    "basic-triple",
+	FormatSymbolic:       "symbolic",
+	FormatFull:           "full",
+	FormatShortOctal:     "short-octal",
+	FormatChmodOctal:     "chmod-octal",
+	FormatChmodSymbolic:  "chmod-symbolic",
+	FormatClassShorthand: "class-shorthand",
+}
+
+// String returns the name of the format, eg `explicit-octal`.
+func (f Format) String() string {
+	if f < 0 || f >= formatCount {
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+	return formatNames[f]
+}
+
+// ParseFormat returns the Format with the given name, as returned by String. Names are case
+// insensitive. An error is returned for unknown names, including "unknown".
+func ParseFormat(name string) (Format, error) {
+	for f := FormatUnknown + 1; f < formatCount; f++ {
+		if strings.EqualFold(name, formatNames[f]) {
+			return f, nil
+		}
+	}
+	return FormatUnknown, fmt.Errorf("unrecognized permission format %q", name)
+}
+
+// MarshalText implements encoding.TextMarshaler for this type. It returns the String() representation.
+func (f Format) MarshalText() ([]byte, error) {
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for this type, following the rules of ParseFormat.
+func (f *Format) UnmarshalText(b []byte) (err error) {
+	*f, err = ParseFormat(string(b))
+	return
+}
+
+// DetectFormat returns the syntax UnmarshalText would parse s as, or FormatUnknown if s is not
+// recognized. A recognized syntax does not guarantee that s parses; `047777777777` is explicit octal
+// but out of range.
+func DetectFormat(s string) Format {
+	return detectFormat([]byte(s))
+}
+
+// SymbolicString renders the permission and special bits of p as a chmod(1) symbolic mode that sets
+// every class absolutely, eg `u=rwxs,g=rx,o=rx`. If group is set, classes with identical rights are
+// merged into one clause as an administrator would write them, eg `ug=rw,o=r`, or `a=r` if every class
+// is the same. File type bits are ignored.
+func (p Perm) SymbolicString(group bool) string {
+	return chmodSymbolic(p, group)
+}
+
+func chmodSymbolic(p Perm, group bool) string {
+	var rights [3]string
+	for c := ClassOwner; c <= ClassOther; c++ {
+		if a := p.Access(c); a != AccessNone {
+			rights[c] = a.String()
+		}
+		if fs.FileMode(p)&lsSpecial[c].bit != 0 {
+			rights[c] += string(lsSpecial[c].letter)
+		}
+	}
+	if group && rights[0] == rights[1] && rights[1] == rights[2] {
+		return "a=" + rights[0]
+	}
+	var clauses []string
+	var done [3]bool
+	for c := range rights {
+		if done[c] {
+			continue
+		}
+		who := string("ugo"[c])
+		for d := c + 1; group && d < len(rights); d++ {
+			if rights[d] == rights[c] {
+				who += string("ugo"[d])
+				done[d] = true
+			}
+		}
+		clauses = append(clauses, who+"="+rights[c])
+	}
+	return strings.Join(clauses, ",")
+}
+
+// FormatAs renders p in the syntax f, so that parsing the result (with WithLenient for
+// FormatShortOctal, or WithAnsible for the chmod formats) yields p again. An error is returned if f
+// cannot represent p exactly; for example only FormatFull and the explicit octal form can carry file
+// type bits, and FormatBasicSingle requires every class to have the same rights.
+func (p Perm) FormatAs(f Format) (string, error) {
+	m := fs.FileMode(p)
+	perm := m&^fs.ModePerm == 0
+	switch f {
+	case FormatImplicitOctal:
+		if p >= 0o100 {
+			return fmt.Sprintf("%o", uint32(p)), nil
+		}
+	case FormatExplicitOctal:
+		return fmt.Sprintf("0%03o", uint32(p)), nil
+	case FormatBasicSingle:
+		if a := p.Access(ClassOwner); perm && p.Access(ClassGroup) == a && p.Access(ClassOther) == a {
+			return Perm(m >> 6).String()[7:], nil
+		}
+	case FormatBasicTriple:
+		if perm {
+			return p.String()[1:], nil
+		}
+	case FormatSymbolic:
+		if perm {
+			return symbolicMinimal(p), nil
+		}
+	case FormatFull:
+		return p.String(), nil
+	case FormatShortOctal:
+		if p <= 0o77 {
+			return fmt.Sprintf("%o", uint32(p)), nil
+		}
+	case FormatChmodOctal:
+		if m&^chmodBits == 0 {
+			return fmt.Sprintf("%04o", unixMode(p)), nil
+		}
+	case FormatChmodSymbolic:
+		if m&^chmodBits == 0 {
+			return chmodSymbolic(p, true), nil
+		}
+	case FormatClassShorthand:
+		if perm {
+			return classShorthand(p), nil
+		}
+	default:
+		return "", fmt.Errorf("cannot render permission in format %v", f)
+	}
+	return "", fmt.Errorf("permission %v cannot be represented in format %v", p, f)
+}
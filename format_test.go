@@ -0,0 +1,143 @@
+package posixperm
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+)
+
+func TestParseFormat(t *testing.T) {
+	for f := FormatUnknown + 1; f < formatCount; f++ {
+		g, err := ParseFormat(f.String())
+		if err != nil || g != f {
+			t.Errorf("with %v, parsed name back to %v, %v", f, g, err)
+		}
+	}
+	if f, err := ParseFormat("EXPLICIT-OCTAL"); err != nil || f != FormatExplicitOctal {
+		t.Errorf("expected case insensitive match, got %v, %v", f, err)
+	}
+	for _, name := range []string{"", "unknown", "octal"} {
+		if f, err := ParseFormat(name); err == nil {
+			t.Errorf("got nil error for %q, parsed to %v", name, f)
+		}
+	}
+	var v struct{ F Format }
+	if err := json.Unmarshal([]byte(`{"F":"basic-triple"}`), &v); err != nil || v.F != FormatBasicTriple {
+		t.Errorf("expected basic-triple from JSON, got %v, %v", v.F, err)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	C := []struct {
+		s string
+		f Format
+	}{
+		{"644", FormatImplicitOctal},
+		{"0644", FormatExplicitOctal},
+		{"0o644", FormatExplicitOctal},
+		{"r-x", FormatBasicSingle},
+		{"rwxr-xr-x", FormatBasicTriple},
+		{"a=rx u+w", FormatSymbolic},
+		{"drwxr-xr-x", FormatFull},
+		{"75", FormatShortOctal},
+		{"u=rwx,go=rx", FormatUnknown},
+		{"bogus", FormatUnknown},
+	}
+	for _, c := range C {
+		if f := DetectFormat(c.s); f != c.f {
+			t.Errorf("with %q, expected %v. got %v", c.s, c.f, f)
+		}
+	}
+}
+
+func TestFormatAsRoundTrip(t *testing.T) {
+	P := []Perm{0o000, 0o007, 0o044, 0o644, 0o755, 0o777, 0o555,
+		Perm(fs.ModeSetuid | 0o755), Perm(fs.ModeSticky | fs.ModeSetgid | 0o777), Perm(fs.ModeDir | 0o750)}
+	for _, p := range P {
+		for f := FormatUnknown + 1; f < formatCount; f++ {
+			s, err := p.FormatAs(f)
+			if err != nil {
+				continue
+			}
+			var opts []Option
+			switch f {
+			case FormatShortOctal:
+				opts = append(opts, WithLenient())
+			case FormatChmodOctal, FormatChmodSymbolic:
+				opts = append(opts, WithAnsible())
+			default:
+				if d := DetectFormat(s); d != f {
+					t.Errorf("with %v as %v, rendered %q which is detected as %v", p, f, s, d)
+				}
+			}
+			q, err := FromString(s, opts...)
+			if err != nil || q != p {
+				t.Errorf("with %v as %v, rendered %q which parses to %v, %v", p, f, s, q, err)
+			}
+		}
+	}
+}
+
+func TestFormatAs(t *testing.T) {
+	C := []struct {
+		p  Perm
+		f  Format
+		s  string
+		ok bool
+	}{
+		{0o644, FormatImplicitOctal, "644", true},
+		{0o044, FormatImplicitOctal, "", false},
+		{0o644, FormatExplicitOctal, "0644", true},
+		{0o555, FormatBasicSingle, "r-x", true},
+		{0o755, FormatBasicSingle, "", false},
+		{0o750, FormatBasicTriple, "rwxr-x---", true},
+		{Perm(fs.ModeSetuid | 0o755), FormatBasicTriple, "", false},
+		{0o755, FormatSymbolic, "a=rx u+w", true},
+		{Perm(fs.ModeDir | 0o750), FormatFull, "drwxr-x---", true},
+		{0o75, FormatShortOctal, "75", true},
+		{0o644, FormatShortOctal, "", false},
+		{Perm(fs.ModeSetuid | 0o755), FormatChmodOctal, "4755", true},
+		{Perm(fs.ModeDir | 0o755), FormatChmodOctal, "", false},
+		{Perm(fs.ModeSetgid | fs.ModeSticky | 0o750), FormatChmodSymbolic, "u=rwx,g=rxs,o=t", true},
+		{0o644, FormatUnknown, "", false},
+	}
+	for _, c := range C {
+		s, err := c.p.FormatAs(c.f)
+		if c.ok && (err != nil || s != c.s) {
+			t.Errorf("with %v as %v, expected %q. got %q, %v", c.p, c.f, c.s, s, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %v as %v, expected error. got %q", c.p, c.f, s)
+		}
+	}
+}
+
+func TestSymbolicString(t *testing.T) {
+	C := []struct {
+		p       Perm
+		grouped string
+		flat    string
+	}{
+		{0o664, "ug=rw,o=r", "u=rw,g=rw,o=r"},
+		{0o755, "u=rwx,go=rx", "u=rwx,g=rx,o=rx"},
+		{0o444, "a=r", "u=r,g=r,o=r"},
+		{0o000, "a=", "u=,g=,o="},
+		{0o707, "uo=rwx,g=", "u=rwx,g=,o=rwx"},
+		{Perm(fs.ModeSetuid | fs.ModeSetgid | 0o775), "ug=rwxs,o=rx", "u=rwxs,g=rwxs,o=rx"},
+		{Perm(fs.ModeSticky | 0o777), "ug=rwx,o=rwxt", "u=rwx,g=rwx,o=rwxt"},
+		{Perm(fs.ModeDir | 0o750), "u=rwx,g=rx,o=", "u=rwx,g=rx,o="},
+	}
+	for _, c := range C {
+		if s := c.p.SymbolicString(true); s != c.grouped {
+			t.Errorf("with %v grouped, expected %q. got %q", c.p, c.grouped, s)
+		}
+		if s := c.p.SymbolicString(false); s != c.flat {
+			t.Errorf("with %v, expected %q. got %q", c.p, c.flat, s)
+		}
+		for _, s := range []string{c.grouped, c.flat} {
+			if q, err := FromString(s, WithAnsible()); err != nil || q != c.p&Perm(chmodBits) {
+				t.Errorf("with %q, expected %v. got %v, %v", s, c.p&Perm(chmodBits), q, err)
+			}
+		}
+	}
+}
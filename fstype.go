@@ -0,0 +1,43 @@
+package posixperm
+
+import "io/fs"
+
+// syntheticModes lists the filesystem types, as named by FilesystemType, that do not store modes but
+// synthesize them from mount options, so that their modes can neither be audited nor changed.
+var syntheticModes = map[string]bool{
+	"vfat":  true,
+	"exfat": true,
+	"ntfs":  true,
+	"ntfs3": true,
+}
+
+// SyntheticModes reports whether filesystems of type fstype, as named by FilesystemType, synthesize
+// the modes of their files from mount options (eg FAT and NTFS), so that findings about them are
+// noise and chmod either fails or has no lasting effect.
+func SyntheticModes(fstype string) bool {
+	return syntheticModes[fstype]
+}
+
+// SkipSyntheticModes makes ApplyExpr skip directories on filesystems for which SyntheticModes
+// reports true, along with their contents, such as a FAT formatted USB stick mounted within the tree.
+// The filesystem type is looked up once per device.
+func SkipSyntheticModes() ApplyOption {
+	return func(o *applyOptions) { o.fsTypes = make(fsTypes) }
+}
+
+// fsTypes caches the filesystem type of each device.
+type fsTypes map[uint64]string
+
+// synthetic reports whether the directory path, described by fi, is on a filesystem with synthetic
+// modes. If the type cannot be determined, it is assumed to store modes.
+func (c fsTypes) synthetic(path string, fi fs.FileInfo) bool {
+	dev, ok := fileDevice(fi)
+	t, cached := c[dev]
+	if !ok || !cached {
+		t, _ = FilesystemType(path)
+		if ok {
+			c[dev] = t
+		}
+	}
+	return SyntheticModes(t)
+}
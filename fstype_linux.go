@@ -0,0 +1,43 @@
+//go:build linux
+
+package posixperm
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// fsMagic names the filesystem types reported by statfs(2), from linux/magic.h.
+var fsMagic = map[uint32]string{
+	0xEF53:     "ext4", // also ext2 and ext3
+	0x58465342: "xfs",
+	0x9123683E: "btrfs",
+	0x2FC12FC1: "zfs",
+	0x01021994: "tmpfs",
+	0x794C7630: "overlay",
+	0x6969:     "nfs",
+	0xFF534D42: "cifs",
+	0xFE534D42: "smb2",
+	0x65735546: "fuse",
+	0x4D44:     "vfat", // also msdos
+	0x2011BAB0: "exfat",
+	0x5346544E: "ntfs",
+	0x7366746E: "ntfs3",
+	0x9660:     "iso9660",
+	0x9FA0:     "proc",
+	0x62656572: "sysfs",
+}
+
+// FilesystemType returns the type of the filesystem holding path, such as "ext4", "nfs" or "vfat",
+// as reported by statfs(2). Filesystems without a known name are returned as their magic number in
+// hexadecimal, eg "0x73717368". It is only available on Linux.
+func FilesystemType(path string) (string, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", fmt.Errorf("cannot determine filesystem type of %s: %w", path, err)
+	}
+	if name, ok := fsMagic[uint32(st.Type)]; ok {
+		return name, nil
+	}
+	return fmt.Sprintf("%#x", uint32(st.Type)), nil
+}
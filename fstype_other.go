@@ -0,0 +1,14 @@
+//go:build !linux
+
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// FilesystemType returns the type of the filesystem holding path, such as "ext4", "nfs" or "vfat",
+// as reported by statfs(2). It is only available on Linux.
+func FilesystemType(path string) (string, error) {
+	return "", fmt.Errorf("cannot determine filesystem type of %s: %w", path, errors.ErrUnsupported)
+}
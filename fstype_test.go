@@ -0,0 +1,50 @@
+package posixperm
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func TestSyntheticModes(t *testing.T) {
+	C := []struct {
+		fstype string
+		want   bool
+	}{
+		{"vfat", true},
+		{"exfat", true},
+		{"ntfs", true},
+		{"ext4", false},
+		{"nfs", false},
+		{"", false},
+	}
+	for _, c := range C {
+		if got := SyntheticModes(c.fstype); got != c.want {
+			t.Errorf("with %q, expected %v. got %v", c.fstype, c.want, got)
+		}
+	}
+}
+
+func TestFilesystemType(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o600})
+	fstype, err := FilesystemType(root)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil || fstype == "" {
+		t.Fatalf("expected a filesystem type, got %q, %v", fstype, err)
+	}
+	if _, err := FilesystemType(filepath.Join(root, "missing")); err == nil {
+		t.Errorf("expected error for a missing path")
+	}
+	if SyntheticModes(fstype) {
+		t.Skipf("temporary directory is on %s", fstype)
+	}
+	if _, err := ApplyExpr(root, "go+r", SkipSyntheticModes()); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if got := modeOf(t, filepath.Join(root, "a")); got != 0o644 {
+		t.Errorf("expected %s to be changed, got %v", fstype, got)
+	}
+}
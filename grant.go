@@ -0,0 +1,52 @@
+package posixperm
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+// TemporaryGrant applies the chmod(1) mode operand expr (eg `g+w`) to the named file, for maintenance
+// that must briefly open up permissions, and returns a function restoring the mode the file had
+// before. Once ctx is done, the mode is restored automatically, so a grant can be bounded in time with
+// context.WithTimeout; with a context that is never done, such as context.Background, the caller must
+// call revert, typically with defer.
+//
+// revert may be called any number of times, from any goroutine; the mode is restored once and every
+// call returns the outcome. The original mode is restored even if the file was changed again in the
+// meantime. If expr is invalid or the mode cannot be changed, an error is returned and nothing needs
+// reverting.
+func TemporaryGrant(ctx context.Context, path, expr string) (revert func() error, err error) {
+	f, err := chmodFunc(expr)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	before := fi.Mode()
+	if err := os.Chmod(path, f(before, fi.IsDir())&chmodBits); err != nil {
+		return nil, err
+	}
+	var once sync.Once
+	var revertErr error
+	reverted := make(chan struct{})
+	revert = func() error {
+		once.Do(func() {
+			revertErr = os.Chmod(path, before&chmodBits)
+			close(reverted)
+		})
+		return revertErr
+	}
+	if done := ctx.Done(); done != nil {
+		go func() {
+			select {
+			case <-done:
+				revert()
+			case <-reverted:
+			}
+		}()
+	}
+	return revert, nil
+}
@@ -0,0 +1,61 @@
+package posixperm
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestTemporaryGrant(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"f": 0o640})
+	path := filepath.Join(root, "f")
+	revert, err := TemporaryGrant(context.Background(), path, "g+w,o+r")
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if got := modeOf(t, path); got != 0o664 {
+		t.Errorf("expected 0664 during grant, got %v", got)
+	}
+	if err := revert(); err != nil {
+		t.Errorf("got error reverting: %v", err)
+	}
+	if got := modeOf(t, path); got != 0o640 {
+		t.Errorf("expected 0640 after revert, got %v", got)
+	}
+	if err := revert(); err != nil {
+		t.Errorf("got error reverting twice: %v", err)
+	}
+	if _, err := TemporaryGrant(context.Background(), path, "g+q"); err == nil {
+		t.Errorf("expected error for an invalid expression")
+	}
+	if _, err := TemporaryGrant(context.Background(), filepath.Join(root, "missing"), "g+w"); err == nil {
+		t.Errorf("expected error for a missing file")
+	}
+}
+
+func TestTemporaryGrantCancel(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"f": 0o600})
+	path := filepath.Join(root, "f")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	revert, err := TemporaryGrant(ctx, path, "0644")
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if got := modeOf(t, path); got != 0o644 {
+		t.Errorf("expected 0644 during grant, got %v", got)
+	}
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for modeOf(t, path) != 0o600 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := modeOf(t, path); got != 0o600 {
+		t.Errorf("expected 0600 after cancellation, got %v", got)
+	}
+	if err := revert(); err != nil {
+		t.Errorf("got error from revert after cancellation: %v", err)
+	}
+}
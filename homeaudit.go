@@ -0,0 +1,78 @@
+package posixperm
+
+import (
+	"context"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// AuditHomes checks the home directories of a multi-user host for the classic mistakes that expose
+// one user's files to another. Each directory at the top of fsys is taken to be a home directory, so
+// fsys is typically os.DirFS("/home"). It reports:
+//
+//   - home directories readable or writable by other
+//   - .ssh directories, and files within them, writable by group or other, which sshd's StrictModes
+//     rejects, and private keys (`id_*` other than `*.pub`) accessible by group or other, which ssh
+//     refuses to use
+//   - dotfiles in home directories, such as .bashrc or .profile, writable by group or other, which
+//     would let others run commands as the user
+//
+// Only home directories themselves, their dotfiles, and their .ssh trees are read; symbolic links are
+// not followed. Findings are returned in walk order. By default an error reading fsys stops the audit,
+// and is returned in a *TreeError along with the findings so far; see ScanErrorPolicy. Progress can
+// be reported with ScanProgress.
+func AuditHomes(fsys fs.FS, opts ...ScanOption) ([]Finding, error) {
+	return AuditHomesContext(context.Background(), fsys, opts...)
+}
+
+// AuditHomesContext is like AuditHomes, but stops promptly once ctx is done, returning the findings
+// so far along with ctx.Err().
+func AuditHomesContext(ctx context.Context, fsys fs.FS, opts ...ScanOption) ([]Finding, error) {
+	o := newScanOptions(opts)
+	var findings []Finding
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return o.errs.add(p, err, true)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if o.xdev.crosses(d) {
+			return fs.SkipDir
+		}
+		if p == "." {
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		m, err := FromDirEntry(d)
+		if err != nil {
+			return o.errs.add(p, err, true)
+		}
+		found := len(findings)
+		flag := func(problem string) { findings = append(findings, Finding{Path: p, Mode: m, Problem: problem}) }
+		parts := strings.Split(p, "/")
+		inSSH := len(parts) > 1 && parts[1] == ".ssh"
+		switch {
+		case len(parts) == 1:
+			if d.IsDir() && m&0o006 != 0 {
+				flag("home directory is readable or writable by other")
+			}
+		case inSSH && m&0o022 != 0:
+			flag("writable by group or other, which sshd's StrictModes rejects")
+		case inSSH && !d.IsDir() && strings.HasPrefix(path.Base(p), "id_") && !strings.HasSuffix(p, ".pub") && RequirePrivate(m) != nil:
+			flag("private key is accessible by group or other, which ssh refuses")
+		case len(parts) == 2 && !d.IsDir() && strings.HasPrefix(parts[1], ".") && m&0o022 != 0:
+			flag("dotfile is writable by group or other")
+		}
+		o.progress.visit(p, len(findings)-found)
+		if d.IsDir() && len(parts) == 2 && !inSSH {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	o.progress.done()
+	return findings, o.errs.result(err)
+}
@@ -0,0 +1,50 @@
+package posixperm_test
+
+import (
+	"testing"
+
+	"github.com/ironiridis/posixperm"
+	"github.com/ironiridis/posixperm/permtest"
+)
+
+func TestAuditHomes(t *testing.T) {
+	fsys := permtest.MustMapFS(map[string][2]string{
+		"alice/":                    {"0700"},
+		"alice/.bashrc":             {"0644"},
+		"alice/.ssh/":               {"0700"},
+		"alice/.ssh/id_ed25519":     {"0600"},
+		"alice/.ssh/id_ed25519.pub": {"0644"},
+		"bob/":                      {"0755"},
+		"bob/.profile":              {"0664"},
+		"bob/.ssh/":                 {"0775"},
+		"bob/.ssh/id_rsa":           {"0640"},
+		"bob/.ssh/authorized_keys":  {"0666"},
+		"bob/projects/":             {"0777"},
+		"bob/projects/.env":         {"0666"},
+		"carol/":                    {"0707"},
+		"notes.txt":                 {"0666"},
+	})
+	findings, err := posixperm.AuditHomes(fsys)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	want := []string{
+		"bob",
+		"bob/.profile",
+		"bob/.ssh",
+		"bob/.ssh/authorized_keys",
+		"bob/.ssh/id_rsa",
+		"carol",
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("expected findings for %q, got %v", want, findings)
+	}
+	for i, f := range findings {
+		if f.Path != want[i] {
+			t.Errorf("at %d, expected finding for %q. got %v", i, want[i], f)
+		}
+	}
+	if s := findings[5].String(); s != "carol (drwx---rwx): home directory is readable or writable by other" {
+		t.Errorf("unexpected finding string %q", s)
+	}
+}
@@ -0,0 +1,242 @@
+package posixperm
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// AuditImage reports every path of a container image whose mode, in the file system a container
+// started from the image would see, does not satisfy m. fsys holds the image either as an OCI image
+// layout, with an index.json listing a single image, or as the extracted contents of a `docker save`
+// tarball, with a manifest.json listing a single image; see AuditImageArchive for the tarball itself.
+//
+// Layers are applied in order, as a container runtime would, so that a path replaced or removed by a
+// later layer (with a `.wh.` whiteout file, or every entry of a directory with `.wh..wh..opq`) is
+// judged only by what remains. Layers may be uncompressed or gzip compressed. Findings are sorted by
+// path, and are otherwise as for AuditTar.
+func AuditImage(fsys fs.FS, m Matcher) ([]Finding, error) {
+	return auditImage(func(name string) (io.ReadCloser, error) { return fsys.Open(name) }, m)
+}
+
+// AuditImageArchive is like AuditImage, but reads a `docker save` tarball (or an OCI image layout
+// packed in a tar archive) of size bytes from r without extracting it.
+func AuditImageArchive(r io.ReaderAt, size int64, m Matcher) ([]Finding, error) {
+	sr := io.NewSectionReader(r, 0, size)
+	members := make(map[string]*io.SectionReader)
+	links := make(map[string]string)
+	tr := tar.NewReader(sr)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag == tar.TypeSymlink {
+			// newer releases of docker save link each layer.tar to a blob
+			links[imagePath(h.Name)] = imagePath(path.Join(path.Dir(h.Name), h.Linkname))
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		// tar.Reader leaves sr at the start of the member's contents
+		offset, err := sr.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		members[imagePath(h.Name)] = io.NewSectionReader(r, offset, h.Size)
+	}
+	return auditImage(func(name string) (io.ReadCloser, error) {
+		member, ok := members[name]
+		if !ok {
+			member, ok = members[links[name]]
+		}
+		if !ok {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+		return io.NopCloser(io.NewSectionReader(member, 0, member.Size())), nil
+	}, m)
+}
+
+// imageDescriptor is the part of an OCI content descriptor needed to find a blob.
+type imageDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+}
+
+func auditImage(open func(name string) (io.ReadCloser, error), m Matcher) ([]Finding, error) {
+	layers, err := imageLayers(open)
+	if err != nil {
+		return nil, err
+	}
+	modes := make(map[string]fs.FileMode)
+	for _, layer := range layers {
+		if err := applyImageLayer(open, layer, modes); err != nil {
+			return nil, fmt.Errorf("layer %s: %w", layer, err)
+		}
+	}
+	paths := make([]string, 0, len(modes))
+	for p := range modes {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var findings []Finding
+	for _, p := range paths {
+		findings = auditMember(findings, p, modes[p], m)
+	}
+	return findings, nil
+}
+
+// imageLayers returns the names of the layers of the single image described by index.json, or
+// failing that by manifest.json, from the bottom layer up.
+func imageLayers(open func(name string) (io.ReadCloser, error)) ([]string, error) {
+	var index struct {
+		Manifests []imageDescriptor `json:"manifests"`
+	}
+	err := readImageJSON(open, "index.json", &index)
+	if errors.Is(err, fs.ErrNotExist) {
+		var manifest []struct {
+			Layers []string `json:"Layers"`
+		}
+		if err := readImageJSON(open, "manifest.json", &manifest); err != nil {
+			return nil, err
+		}
+		if len(manifest) != 1 {
+			return nil, fmt.Errorf("manifest.json lists %d images, not 1", len(manifest))
+		}
+		return manifest[0].Layers, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for len(index.Manifests) == 1 && strings.HasSuffix(index.Manifests[0].MediaType, ".index.v1+json") {
+		// a nested index, as written by some tools for a single platform
+		d := index.Manifests[0]
+		index.Manifests = nil
+		if err := readImageJSON(open, blobPath(d.Digest), &index); err != nil {
+			return nil, err
+		}
+	}
+	if len(index.Manifests) != 1 {
+		return nil, fmt.Errorf("index.json lists %d manifests, not 1", len(index.Manifests))
+	}
+	var manifest struct {
+		Layers []imageDescriptor `json:"layers"`
+	}
+	if err := readImageJSON(open, blobPath(index.Manifests[0].Digest), &manifest); err != nil {
+		return nil, err
+	}
+	layers := make([]string, len(manifest.Layers))
+	for i, d := range manifest.Layers {
+		layers[i] = blobPath(d.Digest)
+	}
+	return layers, nil
+}
+
+// blobPath returns the path of the blob with the given digest in an OCI image layout.
+func blobPath(digest string) string {
+	alg, hex, _ := strings.Cut(digest, ":")
+	return path.Join("blobs", alg, hex)
+}
+
+func readImageJSON(open func(name string) (io.ReadCloser, error), name string, v any) error {
+	f, err := open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// applyImageLayer applies the whiteouts and members of the layer named name to modes, which maps
+// each path of the image to its mode.
+func applyImageLayer(open func(name string) (io.ReadCloser, error), name string, modes map[string]fs.FileMode) error {
+	f, err := open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var layer io.Reader = r
+	if magic, _ := r.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		layer = gz
+	}
+	// Whiteouts apply only to lower layers, so they are collected and applied before the members.
+	var whiteouts, opaque []string
+	members := make(map[string]fs.FileMode)
+	tr := tar.NewReader(layer)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		p := imagePath(h.Name)
+		dir, base := path.Split(p)
+		switch {
+		case p == ".":
+		case base == ".wh..wh..opq":
+			opaque = append(opaque, path.Clean(dir))
+		case strings.HasPrefix(base, ".wh."):
+			whiteouts = append(whiteouts, path.Join(dir, strings.TrimPrefix(base, ".wh.")))
+		default:
+			members[p] = h.FileInfo().Mode()
+		}
+	}
+	for _, dir := range opaque {
+		removeImageTree(modes, dir, false)
+	}
+	for _, p := range whiteouts {
+		removeImageTree(modes, p, true)
+	}
+	for p, mode := range members {
+		if !mode.IsDir() {
+			// a file replacing a directory hides its contents
+			removeImageTree(modes, p, false)
+		}
+		modes[p] = mode
+	}
+	return nil
+}
+
+// removeImageTree removes everything below p from modes, and p itself if self is set.
+func removeImageTree(modes map[string]fs.FileMode, p string, self bool) {
+	if self {
+		delete(modes, p)
+	}
+	prefix := p + "/"
+	if p == "." {
+		prefix = ""
+	}
+	for q := range modes {
+		if strings.HasPrefix(q, prefix) {
+			delete(modes, q)
+		}
+	}
+}
+
+// imagePath returns name, as found in a tar archive, as a path relative to the root of the archive.
+func imagePath(name string) string {
+	return path.Clean(strings.TrimLeft(name, "/"))
+}
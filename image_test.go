@@ -0,0 +1,170 @@
+package posixperm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+// imageLayer returns a tar archive holding entries, gzip compressed if gz is set. Entries without a
+// Typeflag are regular files.
+func imageLayer(t *testing.T, gz bool, entries ...tar.Header) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var zw *gzip.Writer
+	if gz {
+		zw = gzip.NewWriter(&buf)
+		w = zw
+	}
+	tw := tar.NewWriter(w)
+	for i := range entries {
+		if entries[i].Typeflag == 0 {
+			entries[i].Typeflag = tar.TypeReg
+		}
+		if err := tw.WriteHeader(&entries[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.Bytes()
+}
+
+// testImageLayers are the layers of a test image, from the bottom up.
+func testImageLayers(t *testing.T) [][]byte {
+	return [][]byte{
+		imageLayer(t, true,
+			tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0o755},
+			tar.Header{Name: "./etc/", Typeflag: tar.TypeDir, Mode: 0o755},
+			tar.Header{Name: "./etc/shadow", Mode: 0o666},
+			tar.Header{Name: "./bin/", Typeflag: tar.TypeDir, Mode: 0o755},
+			tar.Header{Name: "./bin/su", Mode: 0o4755},
+			tar.Header{Name: "./opt/app/", Typeflag: tar.TypeDir, Mode: 0o777},
+			tar.Header{Name: "./opt/app/cache", Mode: 0o666},
+			tar.Header{Name: "./var/log", Mode: 0o666},
+		),
+		imageLayer(t, false,
+			tar.Header{Name: "etc/.wh.shadow", Mode: 0o600},
+			tar.Header{Name: "bin/su", Mode: 0o755},
+			tar.Header{Name: "opt/app/", Typeflag: tar.TypeDir, Mode: 0o755},
+			tar.Header{Name: "opt/app/.wh..wh..opq", Mode: 0o600},
+			tar.Header{Name: "opt/app/run", Mode: 0o777},
+			tar.Header{Name: "lib", Typeflag: tar.TypeSymlink, Linkname: "usr/lib", Mode: 0o777},
+		),
+	}
+}
+
+var testImageFindings = []Finding{
+	{"opt/app/run", 0o777, "mode does not satisfy <=0755"},
+	{"var/log", 0o666, "mode does not satisfy <=0755"},
+}
+
+func TestAuditImageOCI(t *testing.T) {
+	fsys := fstest.MapFS{}
+	blob := func(b []byte) imageDescriptor {
+		sum := sha256.Sum256(b)
+		d := imageDescriptor{Digest: "sha256:" + hex.EncodeToString(sum[:])}
+		fsys[blobPath(d.Digest)] = &fstest.MapFile{Data: b}
+		return d
+	}
+	var manifest struct {
+		Layers []imageDescriptor `json:"layers"`
+	}
+	for _, layer := range testImageLayers(t) {
+		manifest.Layers = append(manifest.Layers, blob(layer))
+	}
+	b, _ := json.Marshal(manifest)
+	index, _ := json.Marshal(map[string][]imageDescriptor{"manifests": {blob(b)}})
+	fsys["index.json"] = &fstest.MapFile{Data: index}
+
+	findings, err := AuditImage(fsys, AtMost(0o755))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !reflect.DeepEqual(findings, testImageFindings) {
+		t.Errorf("expected %v, got %v", testImageFindings, findings)
+	}
+
+	index, _ = json.Marshal(map[string][]imageDescriptor{"manifests": {blob(b), blob(b)}})
+	fsys["index.json"] = &fstest.MapFile{Data: index}
+	if _, err := AuditImage(fsys, AtMost(0o755)); err == nil {
+		t.Errorf("expected error for an index of several images")
+	}
+	delete(fsys, "index.json")
+	if _, err := AuditImage(fsys, AtMost(0o755)); err == nil {
+		t.Errorf("expected error for a directory that is not an image")
+	}
+}
+
+func TestAuditImageArchive(t *testing.T) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	add := func(h tar.Header, b []byte) {
+		h.Size = int64(len(b))
+		if err := tw.WriteHeader(&h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	layers := testImageLayers(t)
+	add(tar.Header{Name: "blobs/sha256/one", Typeflag: tar.TypeReg, Mode: 0o644}, layers[0])
+	add(tar.Header{Name: "one/layer.tar", Typeflag: tar.TypeSymlink, Linkname: "../blobs/sha256/one"}, nil)
+	add(tar.Header{Name: "two/layer.tar", Typeflag: tar.TypeReg, Mode: 0o644}, layers[1])
+	add(tar.Header{Name: "manifest.json", Typeflag: tar.TypeReg, Mode: 0o644},
+		[]byte(`[{"Config":"config.json","RepoTags":["app:latest"],"Layers":["one/layer.tar","two/layer.tar"]}]`))
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := AuditImageArchive(bytes.NewReader(buf.Bytes()), int64(buf.Len()), AtMost(0o755))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if !reflect.DeepEqual(findings, testImageFindings) {
+		t.Errorf("expected %v, got %v", testImageFindings, findings)
+	}
+	if _, err := AuditImageArchive(bytes.NewReader(buf.Bytes()), 1024, AtMost(0o755)); err == nil {
+		t.Errorf("expected error for a truncated archive")
+	}
+}
+
+func TestRemoveImageTree(t *testing.T) {
+	C := []struct {
+		p    string
+		self bool
+		left []string
+	}{
+		{"a", true, []string{"ab", "b"}},
+		{"a", false, []string{"a", "ab", "b"}},
+		{".", false, []string{}},
+	}
+	for _, c := range C {
+		modes := map[string]fs.FileMode{"a": 0, "a/b": 0, "a/b/c": 0, "ab": 0, "b": 0}
+		removeImageTree(modes, c.p, c.self)
+		left := []string{}
+		for p := range modes {
+			left = append(left, p)
+		}
+		sort.Strings(left)
+		if !reflect.DeepEqual(left, c.left) {
+			t.Errorf("with %q and %v, expected %v. got %v", c.p, c.self, c.left, left)
+		}
+	}
+}
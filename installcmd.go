@@ -0,0 +1,187 @@
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// InstallCommand is an install(1) invocation, as extracted from a packaging script. See
+// ParseInstallCommand.
+type InstallCommand struct {
+	// Spec holds the mode, owner, and group that will be applied to every installed path. As with
+	// install(1), the mode defaults to 0755. With -d, Type is fs.ModeDir.
+	Spec FileSpec
+	// Sources are the files to copy, and Dest the file or directory they are copied to. Both are
+	// empty with -d.
+	Sources []string
+	Dest    string
+	// Directories are the directories to create with -d.
+	Directories []string
+}
+
+// parseChmodMode parses a mode as given to chmod(1) or install(1) -m. Numeric modes use the
+// traditional unix encoding of special bits (eg "4755"), and comma separated symbolic clauses are
+// applied starting from no permissions.
+func parseChmodMode(s string) (p Perm, err error) {
+	if fmtChmodOctal.MatchString(s) {
+		err = p.fromChmodOctal([]byte(s))
+		return
+	}
+	if !fmtChmodSymbolic.MatchString(s) {
+		return 0, fmt.Errorf("unrecognized chmod mode %q", s)
+	}
+	e, err := compileChmodExpr(s)
+	if err != nil {
+		return 0, err
+	}
+	return Perm(e.apply(0, false)), nil
+}
+
+// ParseInstallCommand parses an install(1) command line such as `install -m 0755 -o root -g root
+// src dst` into a FileSpec and paths. Words are split following shell quoting rules, but no expansion
+// is performed. Flags that do not affect the resulting modes or ownership (eg -c, -p, -s, -v, -D) are
+// accepted and ignored; unknown flags are an error.
+func ParseInstallCommand(cmdline string) (InstallCommand, error) {
+	args, err := splitShellWords(cmdline)
+	if err != nil {
+		return InstallCommand{}, fmt.Errorf("cannot parse install command %q: %w", cmdline, err)
+	}
+	return ParseInstallArgs(args)
+}
+
+// ParseInstallArgs is like ParseInstallCommand but takes an already split argument vector, whose
+// first element is the command name.
+func ParseInstallArgs(args []string) (c InstallCommand, err error) {
+	if len(args) == 0 || path.Base(args[0]) != "install" {
+		return c, errors.New("not an install command")
+	}
+	o, err := parseInstallOptions(args[1:])
+	if err != nil {
+		return c, err
+	}
+	c.Spec = o.spec
+	operands := o.operands
+	switch {
+	case c.Spec.Type == fs.ModeDir:
+		if len(operands) == 0 {
+			return c, errors.New("install -d command has no directories")
+		}
+		c.Directories = operands
+	case o.targetDir != "":
+		if len(operands) == 0 {
+			return c, errors.New("install command has no sources")
+		}
+		c.Sources, c.Dest = operands, o.targetDir
+	default:
+		if len(operands) < 2 || o.noTargetDir && len(operands) != 2 {
+			return c, errors.New("install command needs a source and a destination")
+		}
+		c.Sources, c.Dest = operands[:len(operands)-1], operands[len(operands)-1]
+	}
+	return c, nil
+}
+
+// installOptions holds the result of parsing the arguments of an install(1) command.
+type installOptions struct {
+	spec        FileSpec
+	operands    []string
+	targetDir   string
+	noTargetDir bool
+}
+
+// parseInstallOptions parses the arguments of an install(1) command, not including the command name,
+// separating the options from the operands. It is shared by ParseInstallArgs and the install form of
+// ParseFileSpec.
+func parseInstallOptions(args []string) (o installOptions, err error) {
+	o.spec.Mode = 0o755 // install(1)'s default, for files and directories alike
+	options := true
+	// value returns the argument of the flag at args[*i], either attached or as the next word
+	value := func(i *int, attached string) (string, error) {
+		if attached != "" {
+			return attached, nil
+		}
+		if *i+1 >= len(args) {
+			return "", fmt.Errorf("install option %q requires an argument", args[*i])
+		}
+		*i++
+		return args[*i], nil
+	}
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if !options || a == "-" || !strings.HasPrefix(a, "-") {
+			o.operands = append(o.operands, a)
+			continue
+		}
+		if a == "--" {
+			options = false
+			continue
+		}
+		if strings.HasPrefix(a, "--") {
+			name, attached, _ := strings.Cut(a[2:], "=")
+			var v string
+			switch name {
+			case "mode", "owner", "group", "target-directory":
+				if v, err = value(&i, attached); err != nil {
+					return
+				}
+			}
+			switch name {
+			case "mode":
+				if o.spec.Mode, err = parseChmodMode(v); err != nil {
+					return
+				}
+			case "owner":
+				o.spec.Owner.User = v
+			case "group":
+				o.spec.Owner.Group = v
+			case "target-directory":
+				o.targetDir = v
+			case "directory":
+				o.spec.Type = fs.ModeDir
+			case "no-target-directory":
+				o.noTargetDir = true
+			case "compare", "preserve-timestamps", "strip", "verbose", "backup":
+			default:
+				return o, fmt.Errorf("unsupported install option %q", a)
+			}
+			continue
+		}
+	flags:
+		for j := 1; j < len(a); j++ {
+			var v string
+			switch a[j] {
+			case 'm', 'o', 'g', 't':
+				if v, err = value(&i, a[j+1:]); err != nil {
+					return
+				}
+			}
+			switch a[j] {
+			case 'm':
+				if o.spec.Mode, err = parseChmodMode(v); err != nil {
+					return
+				}
+			case 'o':
+				o.spec.Owner.User = v
+			case 'g':
+				o.spec.Owner.Group = v
+			case 't':
+				o.targetDir = v
+			case 'd':
+				o.spec.Type = fs.ModeDir
+				continue
+			case 'T':
+				o.noTargetDir = true
+				continue
+			case 'b', 'c', 'C', 'D', 'p', 's', 'v':
+				continue
+			default:
+				return o, fmt.Errorf("unsupported install option %q", "-"+string(a[j]))
+			}
+			break flags // the rest of the word was the flag's argument
+		}
+	}
+	return o, nil
+}
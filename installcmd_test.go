@@ -0,0 +1,54 @@
+package posixperm
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+)
+
+func TestValidInstallCommand(t *testing.T) {
+	root := Ownership{User: "root", Group: "root"}
+	C := []struct {
+		s string
+		v InstallCommand
+	}{
+		{"install -m 0755 -o root -g root src dst", InstallCommand{Spec: FileSpec{Mode: 0o755, Owner: root}, Sources: []string{"src"}, Dest: "dst"}},
+		{"install -m0644 a b c /usr/share/doc", InstallCommand{Spec: FileSpec{Mode: 0o644}, Sources: []string{"a", "b", "c"}, Dest: "/usr/share/doc"}},
+		{"install -Dm 4755 tool /usr/bin/tool", InstallCommand{Spec: FileSpec{Mode: Perm(fs.ModeSetuid | 0o755)}, Sources: []string{"tool"}, Dest: "/usr/bin/tool"}},
+		{"install -d -m 750 -g adm /var/log/app /var/lib/app", InstallCommand{Spec: FileSpec{Mode: 0o750, Owner: Ownership{Group: "adm"}, Type: fs.ModeDir}, Directories: []string{"/var/log/app", "/var/lib/app"}}},
+		{"install --mode=u=rw,go=r --owner=app -t /etc/app conf", InstallCommand{Spec: FileSpec{Mode: 0o644, Owner: Ownership{User: "app"}}, Sources: []string{"conf"}, Dest: "/etc/app"}},
+		{"/usr/bin/install -cv 'my file' \"$DEST\"", InstallCommand{Spec: FileSpec{Mode: 0o755}, Sources: []string{"my file"}, Dest: "$DEST"}},
+		{"install -T -m 600 a b", InstallCommand{Spec: FileSpec{Mode: 0o600}, Sources: []string{"a"}, Dest: "b"}},
+	}
+	for _, c := range C {
+		v, err := ParseInstallCommand(c.s)
+		if err != nil {
+			t.Errorf("with %q, expected %+v. got error: %v", c.s, c.v, err)
+		}
+		if !reflect.DeepEqual(v, c.v) {
+			t.Errorf("with %q, expected %+v. got %+v", c.s, c.v, v)
+		}
+	}
+}
+
+func TestInvalidInstallCommand(t *testing.T) {
+	C := []string{
+		"",
+		"cp a b",
+		"install",
+		"install a",
+		"install -d",
+		"install -m",
+		"install -m 0855 a b",
+		"install -Z a b",
+		"install --frobnicate a b",
+		"install -T a b c",
+		"install -t /dir",
+	}
+	for _, c := range C {
+		v, err := ParseInstallCommand(c)
+		if err == nil {
+			t.Errorf("got nil error for %q, parsed to %+v", c, v)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package posixperm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// JSONNumbers is a policy for interpreting a bare JSON number, rather than a string, as a Perm. There
+// is no safe default: Kubernetes and most JSON producers write modes in decimal (`420` for 0644),
+// while people writing YAML by hand expect `644` to mean 0644. See WithJSONNumbers.
+type JSONNumbers int
+
+const (
+	JSONNumbersReject  JSONNumbers = iota // numbers are an error, so modes must be written as strings
+	JSONNumbersDecimal                    // numbers hold the mode's value, so 420 is 0644
+	JSONNumbersOctal                      // numbers are octal digits, so 644 is 0644
+)
+
+// WithJSONNumbers sets the policy for bare JSON numbers unmarshaled into a Perm. By default they are
+// rejected. Numbers use the traditional unix encoding, where 04000 is setuid, 02000 setgid, and 01000
+// sticky, and values above 07777 are an error. The policy has no effect on strings, which are parsed as
+// usual. For a Perm unmarshaled directly by encoding/json, the policy of the default Parser applies;
+// see SetDefaultParser.
+func WithJSONNumbers(policy JSONNumbers) Option {
+	return func(o *options) { o.jsonNumbers = policy }
+}
+
+// UnmarshalJSON implements json.Unmarshaler for this type. JSON strings are parsed exactly as by
+// UnmarshalText, and numbers according to the JSONNumbers policy of the default Parser, if any. A
+// JSON null leaves p unchanged.
+func (p *Perm) UnmarshalJSON(b []byte) error {
+	return p.parseJSON(b, baseOptions())
+}
+
+// ParseJSON parses the JSON value b, which may be a string or a number, subject to the Parser's
+// Options. It allows a Parser with its own JSONNumbers policy to be used for specific fields.
+func (ps *Parser) ParseJSON(b []byte) (r Perm, err error) {
+	err = r.parseJSON(b, &ps.opts)
+	return
+}
+
+func (p *Perm) parseJSON(b []byte, o *options) error {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.Equal(b, []byte("null")):
+		return nil
+	case len(b) > 0 && b[0] == '"':
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		return p.parse([]byte(s), o)
+	}
+	var v uint64
+	var err error
+	switch o.jsonNumbers {
+	case JSONNumbersDecimal:
+		v, err = strconv.ParseUint(string(b), 10, 32)
+	case JSONNumbersOctal:
+		v, err = strconv.ParseUint(string(b), 8, 32)
+	default:
+		return fmt.Errorf("permission must be a string, not the JSON value %s", b)
+	}
+	if err == nil && v > 0o7777 {
+		err = fmt.Errorf("%s is out of range", b)
+	}
+	if err != nil {
+		err = fmt.Errorf("cannot parse JSON number %s as a permission: %w", b, err)
+		recordParse(FormatChmodOctal, err)
+		return err
+	}
+	r := fromUnix(v)
+	err = o.allowValue(r)
+	recordParse(FormatChmodOctal, err)
+	if err != nil {
+		return err
+	}
+	*p = r
+	return nil
+}
@@ -0,0 +1,79 @@
+package posixperm
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+)
+
+func TestJSONNumbers(t *testing.T) {
+	C := []struct {
+		j      string
+		policy JSONNumbers
+		v      Perm
+		ok     bool
+	}{
+		{`420`, JSONNumbersDecimal, 0o644, true},
+		{`493`, JSONNumbersDecimal, 0o755, true},
+		{`644`, JSONNumbersOctal, 0o644, true},
+		{`"0644"`, JSONNumbersDecimal, 0o644, true},
+		{`"rwxr-xr-x"`, JSONNumbersReject, 0o755, true},
+		{`null`, JSONNumbersReject, 0, true},
+		{`644`, JSONNumbersReject, 0, false},
+		{`689`, JSONNumbersOctal, 0, false},
+		{`-1`, JSONNumbersDecimal, 0, false},
+		{`4.2e2`, JSONNumbersDecimal, 0, false},
+		{`true`, JSONNumbersDecimal, 0, false},
+		{`511`, JSONNumbersDecimal, 0o777, true},
+		{`2541`, JSONNumbersDecimal, Perm(fs.ModeSetuid | 0o755), true},
+		{`7777`, JSONNumbersOctal, Perm(fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky | 0o777), true},
+		{`4096`, JSONNumbersDecimal, 0, false},
+		{`10000`, JSONNumbersOctal, 0, false},
+		{`4294967295`, JSONNumbersDecimal, 0, false},
+	}
+	for _, c := range C {
+		p, err := NewParser(WithJSONNumbers(c.policy)).ParseJSON([]byte(c.j))
+		if c.ok && (err != nil || p != c.v) {
+			t.Errorf("with %s and policy %d, expected %v. got %v, %v", c.j, c.policy, c.v, p, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %s and policy %d, expected error. got %v", c.j, c.policy, p)
+		}
+	}
+	if _, err := NewParser(WithJSONNumbers(JSONNumbersDecimal), WithMaxMode(0o755)).ParseJSON([]byte(`511`)); err == nil {
+		t.Errorf("expected WithMaxMode to apply to numbers")
+	}
+}
+
+func TestJSONNumbersMetrics(t *testing.T) {
+	h := &countingHook{parsed: map[string]int{}, failed: map[string]int{}}
+	SetMetricsHook(h)
+	defer SetMetricsHook(nil)
+
+	pp := NewParser(WithJSONNumbers(JSONNumbersOctal))
+	pp.ParseJSON([]byte(`644`))
+	pp.ParseJSON([]byte(`10000`))
+	if h.parsed["chmod-octal"] != 1 || h.failed["chmod-octal"] != 1 {
+		t.Errorf("expected 1 chmod-octal parse and 1 failure, counted %v and %v", h.parsed, h.failed)
+	}
+}
+
+func TestUnmarshalJSONNumber(t *testing.T) {
+	var d JSONType
+	if err := json.Unmarshal([]byte(`{"P": 420}`), &d); err == nil {
+		t.Errorf("expected numbers to be rejected by default, got %v", d.P)
+	}
+	if err := json.Unmarshal([]byte(`{"P": "0640"}`), &d); err != nil || d.P != 0o640 {
+		t.Errorf("expected 0640, got %v, %v", d.P, err)
+	}
+	if err := json.Unmarshal([]byte(`{"P": null}`), &d); err != nil || d.P != 0o640 {
+		t.Errorf("expected null to leave 0640, got %v, %v", d.P, err)
+	}
+	defer defaultParser.Store(nil)
+	if err := SetDefaultParser(NewParser(WithJSONNumbers(JSONNumbersDecimal))); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"P": 420}`), &d); err != nil || d.P != 0o644 {
+		t.Errorf("expected 0644 with the default Parser, got %v, %v", d.P, err)
+	}
+}
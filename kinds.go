@@ -0,0 +1,104 @@
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// the special bits that only make sense on regular files and directories
+const specialBits = fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+
+// withType composes the type bits t with the permission bits of p, rejecting a p that carries bits
+// other than permission bits and the special bits in allowed.
+func withType(t fs.FileMode, p Perm, allowed fs.FileMode) (Perm, error) {
+	m := fs.FileMode(p)
+	if m.Type() != 0 && m.Type() != t {
+		return 0, fmt.Errorf("mode %v already has a file type other than %v", m, t)
+	}
+	if extra := m &^ (fs.ModeType | fs.ModePerm | allowed); extra != 0 {
+		return 0, fmt.Errorf("mode %v has bits %v that do not apply to file type %v", m, extra, t)
+	}
+	return Perm(t | m&^fs.ModeType), nil
+}
+
+// Dir returns p marked as a directory. Setuid, setgid, and sticky bits are permitted; other non
+// permission bits, or a conflicting file type, cause an error.
+func Dir(p Perm) (Perm, error) {
+	return withType(fs.ModeDir, p, specialBits)
+}
+
+// NamedPipe returns p marked as a named pipe (FIFO). Only permission bits are permitted.
+func NamedPipe(p Perm) (Perm, error) {
+	return withType(fs.ModeNamedPipe, p, 0)
+}
+
+// Socket returns p marked as a Unix domain socket. Only permission bits are permitted.
+func Socket(p Perm) (Perm, error) {
+	return withType(fs.ModeSocket, p, 0)
+}
+
+// Symlink returns the mode of a symbolic link. Symbolic links have no meaningful permissions of their
+// own, so like lstat(2) on Linux this always reports 0777.
+func Symlink() Perm {
+	return Perm(fs.ModeSymlink | 0o777)
+}
+
+// Device returns p marked as a device file; a character device if char is set, otherwise a block
+// device. Only permission bits are permitted.
+func Device(p Perm, char bool) (Perm, error) {
+	t := fs.ModeDevice
+	if char {
+		t |= fs.ModeCharDevice
+	}
+	return withType(t, p, 0)
+}
+
+// ValidateForType checks whether p makes sense for a file of type t (eg fs.ModeDir, or 0 for a
+// regular file), returning an error describing each questionable combination or nil if there are
+// none. Most of these combinations are silently accepted by chmod(2) but either have no effect or do
+// something other than intended, so this is meant for linting configuration before applying it.
+func (p Perm) ValidateForType(t fs.FileMode) error {
+	m := fs.FileMode(p)
+	t = t.Type()
+	var errs []error
+	if m.Type() != 0 && m.Type() != t {
+		errs = append(errs, fmt.Errorf("mode %v has file type %v, expected %v", m, m.Type(), t))
+	}
+	switch {
+	case t == 0:
+		if m&fs.ModeSticky != 0 {
+			errs = append(errs, fmt.Errorf("mode %v: sticky bit has no effect on regular files", m))
+		}
+		if m&fs.ModeSetuid != 0 && m&0o100 == 0 {
+			errs = append(errs, fmt.Errorf("mode %v: setuid has no effect without owner execute", m))
+		}
+		if m&fs.ModeSetgid != 0 && m&0o010 == 0 {
+			errs = append(errs, fmt.Errorf("mode %v: setgid without group execute requests mandatory locking", m))
+		}
+	case t == fs.ModeDir:
+		if m&fs.ModeSetuid != 0 {
+			errs = append(errs, fmt.Errorf("mode %v: setuid has no effect on directories", m))
+		}
+		if m&0o111 == 0 {
+			errs = append(errs, fmt.Errorf("mode %v: directory cannot be searched by anyone", m))
+		}
+		for _, c := range []struct {
+			name string
+			r, x fs.FileMode
+		}{{"owner", 0o400, 0o100}, {"group", 0o040, 0o010}, {"other", 0o004, 0o001}} {
+			if m&c.r != 0 && m&c.x == 0 {
+				errs = append(errs, fmt.Errorf("mode %v: %s can list but not access directory entries", m, c.name))
+			}
+		}
+	case t == fs.ModeSymlink:
+		if m&fs.ModePerm != 0o777 || m&specialBits != 0 {
+			errs = append(errs, fmt.Errorf("mode %v: symbolic link permissions are ignored, the target's apply", m))
+		}
+	default:
+		if m&specialBits != 0 {
+			errs = append(errs, fmt.Errorf("mode %v: special bits have no effect on file type %v", m, t))
+		}
+	}
+	return errors.Join(errs...)
+}
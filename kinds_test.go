@@ -0,0 +1,83 @@
+package posixperm
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestValidKinds(t *testing.T) {
+	C := []struct {
+		f func() (Perm, error)
+		v string
+	}{
+		{func() (Perm, error) { return Dir(0o755) }, "drwxr-xr-x"},
+		{func() (Perm, error) { return Dir(Perm(fs.ModeDir | 0o700)) }, "drwx------"},
+		{func() (Perm, error) { return Dir(Perm(fs.ModeSticky | 0o777)) }, "dtrwxrwxrwx"},
+		{func() (Perm, error) { return NamedPipe(0o600) }, "prw-------"},
+		{func() (Perm, error) { return Socket(0o777) }, "Srwxrwxrwx"},
+		{func() (Perm, error) { return Symlink(), nil }, "Lrwxrwxrwx"},
+		{func() (Perm, error) { return Device(0o660, false) }, "Drw-rw----"},
+		{func() (Perm, error) { return Device(0o666, true) }, "Dcrw-rw-rw-"},
+	}
+	for _, c := range C {
+		p, err := c.f()
+		if err != nil {
+			t.Errorf("expected %s, got error: %v", c.v, err)
+		}
+		if p.String() != c.v {
+			t.Errorf("expected %s, got %s", c.v, p)
+		}
+	}
+}
+
+func TestInvalidKinds(t *testing.T) {
+	C := []func() (Perm, error){
+		func() (Perm, error) { return Dir(Perm(fs.ModeNamedPipe | 0o755)) },
+		func() (Perm, error) { return Dir(Perm(fs.ModeAppend | 0o755)) },
+		func() (Perm, error) { return NamedPipe(Perm(fs.ModeSetuid | 0o600)) },
+		func() (Perm, error) { return Socket(Perm(fs.ModeDir | 0o755)) },
+		func() (Perm, error) { return Device(Perm(fs.ModeSticky|0o660), false) },
+		func() (Perm, error) { return Device(Perm(fs.ModeDevice|0o660), true) },
+	}
+	for _, c := range C {
+		p, err := c()
+		if err == nil {
+			t.Errorf("got nil error, composed %v", p)
+		}
+	}
+}
+
+func TestValidateForType(t *testing.T) {
+	C := []struct {
+		p  Perm
+		t  fs.FileMode
+		ok bool
+	}{
+		{0o644, 0, true},
+		{Perm(fs.ModeSetuid | 0o755), 0, true},
+		{Perm(fs.ModeSticky | 0o644), 0, false},
+		{Perm(fs.ModeSetuid | 0o644), 0, false},
+		{Perm(fs.ModeSetgid | 0o644), 0, false},
+		{0o755, fs.ModeDir, true},
+		{Perm(fs.ModeDir | 0o750), fs.ModeDir, true},
+		{Perm(fs.ModeSetgid | fs.ModeSticky | 0o777), fs.ModeDir, true},
+		{0o644, fs.ModeDir, false},
+		{0o000, fs.ModeDir, false},
+		{0o711, fs.ModeDir, true},
+		{Perm(fs.ModeSetuid | 0o755), fs.ModeDir, false},
+		{Perm(fs.ModeNamedPipe | 0o755), fs.ModeDir, false},
+		{0o777, fs.ModeSymlink, true},
+		{0o755, fs.ModeSymlink, false},
+		{0o600, fs.ModeNamedPipe, true},
+		{Perm(fs.ModeSticky | 0o600), fs.ModeSocket, false},
+	}
+	for _, c := range C {
+		err := c.p.ValidateForType(c.t)
+		if c.ok && err != nil {
+			t.Errorf("with %v for type %v, got error: %v", c.p, c.t, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %v for type %v, got nil error", c.p, c.t)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package posixperm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WithContinueOnError makes ParseList parse every item even after some fail, collecting the
+// failures in a *ListError, rather than stopping at the first one. It has no effect on single values.
+func WithContinueOnError() Option {
+	return func(o *options) { o.continueOnError = true }
+}
+
+// ItemError records the failure to parse one item of a list.
+type ItemError struct {
+	Index int
+	Input string
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying parse error.
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// ListError aggregates the failures of a ParseList call made with WithContinueOnError.
+type ListError struct {
+	Items []*ItemError
+}
+
+// Error implements the error interface, summarizing every failed item.
+func (e *ListError) Error() string {
+	msgs := make([]string, len(e.Items))
+	for i, item := range e.Items {
+		msgs[i] = item.Error()
+	}
+	return fmt.Sprintf("%d of the items could not be parsed: %s", len(e.Items), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed items, for use with errors.Is and errors.As.
+func (e *ListError) Unwrap() []error {
+	errs := make([]error, len(e.Items))
+	for i, item := range e.Items {
+		errs[i] = item
+	}
+	return errs
+}
+
+// ParseList parses each of inputs like FromString. By default it stops at the first item that fails,
+// returning the values parsed before it and an *ItemError. With WithContinueOnError, every item is
+// parsed and a value is returned for each, left as zero where parsing failed, along with a *ListError
+// listing the failures.
+func ParseList(inputs []string, opts ...Option) ([]Perm, error) {
+	o := *baseOptions()
+	o.cache = nil // results cached by the default Parser do not account for opts
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o.parseList(inputs)
+}
+
+// ParseList is like the package level ParseList, using the Parser's Options.
+func (ps *Parser) ParseList(inputs []string) ([]Perm, error) {
+	return ps.opts.parseList(inputs)
+}
+
+func (o *options) parseList(inputs []string) ([]Perm, error) {
+	out := make([]Perm, len(inputs))
+	var failed []*ItemError
+	for i, s := range inputs {
+		if err := out[i].parse([]byte(s), o); err != nil {
+			item := &ItemError{Index: i, Input: s, Err: err}
+			if !o.continueOnError {
+				return out[:i], item
+			}
+			failed = append(failed, item)
+		}
+	}
+	if failed != nil {
+		return out, &ListError{Items: failed}
+	}
+	return out, nil
+}
+
+// ParseBatch parses each of inputs into the corresponding element of out, following the rules of
+// UnmarshalText. It is intended for scanners parsing very many mode strings: options are resolved
+// once, and parsing the common syntaxes does not allocate, so a caller reusing out (and its input
+// buffers) across batches parses without per-item allocations. If every input parses, nil is
+// returned; otherwise the result holds the error for each input at its index, and the elements of out
+// for failed inputs are left unchanged. ParseBatch panics if out is shorter than inputs.
+func ParseBatch(inputs [][]byte, out []Perm) []error {
+	return baseOptions().parseBatch(inputs, out)
+}
+
+// ParseBatch is like the package level ParseBatch, using the Parser's Options.
+func (ps *Parser) ParseBatch(inputs [][]byte, out []Perm) []error {
+	return ps.opts.parseBatch(inputs, out)
+}
+
+func (o *options) parseBatch(inputs [][]byte, out []Perm) []error {
+	if len(out) < len(inputs) {
+		panic(fmt.Sprintf("posixperm: ParseBatch of %d inputs into %d outputs", len(inputs), len(out)))
+	}
+	var errs []error
+	for i, b := range inputs {
+		if err := out[i].parse(b, o); err != nil {
+			if errs == nil {
+				errs = make([]error, len(inputs))
+			}
+			errs[i] = err
+		}
+	}
+	return errs
+}
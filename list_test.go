@@ -0,0 +1,98 @@
+package posixperm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	v, err := ParseList([]string{"0644", "rwx", "a=rx u+w"})
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if want := []Perm{0o644, 0o777, 0o755}; !reflect.DeepEqual(v, want) {
+		t.Errorf("expected %v, got %v", want, v)
+	}
+}
+
+func TestParseListStopsAtFirstError(t *testing.T) {
+	v, err := ParseList([]string{"0644", "bogus", "0755", "0999"})
+	var item *ItemError
+	if !errors.As(err, &item) {
+		t.Fatalf("expected *ItemError, got %v", err)
+	}
+	if item.Index != 1 || item.Input != "bogus" {
+		t.Errorf("unexpected item error %+v", item)
+	}
+	if want := []Perm{0o644}; !reflect.DeepEqual(v, want) {
+		t.Errorf("expected %v, got %v", want, v)
+	}
+}
+
+func TestParseListContinueOnError(t *testing.T) {
+	inputs := []string{"0644", "bogus", "0755", "0999", "644"}
+	v, err := NewParser(WithContinueOnError(), WithStrictOctal()).ParseList(inputs)
+	var list *ListError
+	if !errors.As(err, &list) {
+		t.Fatalf("expected *ListError, got %v", err)
+	}
+	if len(list.Items) != 3 || list.Items[0].Index != 1 || list.Items[1].Index != 3 || list.Items[2].Index != 4 {
+		t.Errorf("unexpected failures %v", list)
+	}
+	if want := []Perm{0o644, 0, 0o755, 0, 0}; !reflect.DeepEqual(v, want) {
+		t.Errorf("expected %v, got %v", want, v)
+	}
+	var item *ItemError
+	if !errors.As(err, &item) || item.Index != 1 {
+		t.Errorf("expected errors.As to find the first *ItemError, got %v", item)
+	}
+}
+
+func TestParseBatch(t *testing.T) {
+	inputs := [][]byte{[]byte("0644"), []byte("bogus"), []byte("a=rx u+w"), []byte("7")}
+	out := []Perm{1, 2, 3, 4, 5}
+	errs := ParseBatch(inputs, out)
+	if len(errs) != len(inputs) || errs[0] != nil || errs[1] == nil || errs[2] != nil || errs[3] == nil {
+		t.Errorf("expected errors for items 1 and 3, got %v", errs)
+	}
+	want := []Perm{0o644, 2, 0o755, 4, 5}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Errorf("at %d, expected %v. got %v", i, want[i], out[i])
+		}
+	}
+	if errs := NewParser(WithLenient()).ParseBatch(inputs[2:], out); errs != nil || out[1] != 0o007 {
+		t.Errorf("expected lenient batch to parse, got %v, %v", out[:2], errs)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for short output")
+		}
+	}()
+	ParseBatch(inputs, out[:1])
+}
+
+func TestParseBatchAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	inputs := [][]byte{[]byte("0644"), []byte("644"), []byte("0o755"), []byte("rw-"), []byte("rwxr-x---"),
+		[]byte("-rw-r--r--"), []byte("drwxr-xr-x"), []byte("a=rx u+w")}
+	out := make([]Perm, len(inputs))
+	if n := testing.AllocsPerRun(100, func() { ParseBatch(inputs, out) }); n != 0 {
+		t.Errorf("expected no allocations, got %v", n)
+	}
+}
+
+func BenchmarkParseBatch(b *testing.B) {
+	inputs := make([][]byte, 1000)
+	for i := range inputs {
+		inputs[i] = []byte([]string{"0644", "0755", "-rw-r--r--", "drwxr-xr-x", "a=rx u+w"}[i%5])
+	}
+	out := make([]Perm, len(inputs))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ParseBatch(inputs, out)
+	}
+}
@@ -7,10 +7,13 @@
 //	`644` -- implied octal form, specifying read/write for owner, read-only for group/other
 //	`0644` -- as above, but explicit octal form
 //	`0o644` -- as above, but explicit octal form satisfying YAML 1.2 etc
+//	`7` / `75` -- 1 or 2 digit octal as chmod accepts them (0007, 0075), only with WithLenient
 //	`a=r` -- symbolic form assigning read permission to all
 //	`a=rwx o-w` -- symbolic form assigning r/w/x to all but removing write from other
 //	`ug=rx u+w` -- symbolic form granting read/execute to owner/group, adding write to owner
 //	`ug=rxu+w` -- symbolic form as above but without space separator
+//	`uugo=r` / `ao-w` -- actor letters may repeat, and `a` always means every actor, as with chmod
+//	`u:rw g:r o:-` -- per-class shorthand as emitted by some container tools, also comma separated
 //
 // It's also possible to use long form permission styles:
 //
@@ -24,6 +27,7 @@ import (
 	"io/fs"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 // a naked "644" style permissions expression
@@ -32,9 +36,12 @@ var fmtImplicitInt = regexp.MustCompile(`^[1-7][0-7]{2,}$`)
 // a "0644" or "0o644" (eg Go and YAML 1.2) style permissions expression
 var fmtExplicitInt = regexp.MustCompile(`^0o?[0-7]{3,}$`)
 
-// a series of actor/modifier/permission tuples (eg "a=rwx o-w" or "u=rw g=r")
-var fmtSymbolicMatch = regexp.MustCompile(`^((a|[ugo]{1,3})([-=+])([rwx]{1,3})\s?)+$`)
-var fmtSymbolicExtract = regexp.MustCompile(`(a|[ugo]{1,3})([-=+])([rwx]{1,3})`)
+// a series of actor/modifier/permission tuples (eg "a=rwx o-w" or "u=rw g=r"); as with chmod, actor
+// letters may repeat and "a" may be combined with the others
+var fmtSymbolicMatch = regexp.MustCompile(`^(([ugoa]+)([-=+])([rwx]{1,3})\s?)+$`)
+
+// a 1 or 2 digit octal expression (eg "7" or "75") as accepted by chmod, only with WithLenient
+var fmtShortInt = regexp.MustCompile(`^(0o)?[0-7]{1,2}$`)
 
 // a single "rwx" shorthand applying the same permission to user/group/other
 var fmtBasicSingle = regexp.MustCompile(`^(r|-)(w|-)(x|-)$`)
@@ -70,16 +77,29 @@ func (p *Perm) fromExplicit(b []byte) error {
 	return nil
 }
 
+func (p *Perm) fromShort(b []byte) error {
+	v, err := strconv.ParseUint(strings.TrimPrefix(string(b), "0o"), 8, 32)
+	if err != nil {
+		return fmt.Errorf("cannot parse short octal permission value %q: %w", b, err)
+	}
+	*p = Perm(v)
+	return nil
+}
+
 func (p *Perm) fromSymbolic(b []byte) error {
-	matches := fmtSymbolicExtract.FindAllSubmatch(b, -1)
-	// return fmt.Errorf("matches: %q", matches)
+	// b has already matched fmtSymbolicMatch, so it can be scanned without further checks (and
+	// without the allocations of extracting submatches).
 	var perm Perm
-	for _, permexpr := range matches {
+	for i := 0; i < len(b); {
+		if b[i] == ' ' || b[i] == '\t' || b[i] == '\n' || b[i] == '\f' || b[i] == '\r' {
+			i++
+			continue
+		}
 		var actor Perm
-		for _, sym := range permexpr[1] {
-			switch sym {
-			case 'a': // a == all actors (u + g + o)
-				actor = 0o777
+		for ; b[i] != '+' && b[i] != '-' && b[i] != '='; i++ {
+			switch b[i] {
+			case 'a': // a == all actors (u + g + o), whatever else is named
+				actor = actor | 0o777
 			case 'u': // user owner actor
 				actor = actor | 0o700
 			case 'g': // group member actor
@@ -88,9 +108,11 @@ func (p *Perm) fromSymbolic(b []byte) error {
 				actor = actor | 0o007
 			}
 		}
+		op := b[i]
+		i++
 		var actorperm Perm
-		for _, sym := range permexpr[3] {
-			switch sym {
+		for ; i < len(b) && (b[i] == 'r' || b[i] == 'w' || b[i] == 'x'); i++ {
+			switch b[i] {
 			case 'r':
 				actorperm = actorperm | 0o444
 			case 'w':
@@ -99,7 +121,7 @@ func (p *Perm) fromSymbolic(b []byte) error {
 				actorperm = actorperm | 0o111
 			}
 		}
-		switch permexpr[2][0] {
+		switch op {
 		case '+':
 			perm = perm | (actor & actorperm)
 		case '-':
@@ -163,7 +185,12 @@ func (p *Perm) fromBasicTriple(b []byte) error {
 }
 
 func (p *Perm) fromFull(b []byte) error {
-	m := fmtFull.FindSubmatch(b)
+	// b has already matched fmtFull, so the 9 permission bytes are at the end.
+	n := len(b) - 9
+	m := [11][]byte{1: b[:n]}
+	for i := 0; i < 9; i++ {
+		m[i+2] = b[n+i : n+i+1]
+	}
 
 	var perm Perm
 	for _, attr := range m[1] {
@@ -230,25 +257,56 @@ func (p *Perm) fromFull(b []byte) error {
 
 // UnmarshalText implements encoding.TextUnmarshaler for this type. It checks for several conventional
 // formats for basic file permissions, and also understands the full format returned by fs.FileMode's
-// String() method.
+// String() method. If a default Parser has been installed with SetDefaultParser, its Options apply.
 func (p *Perm) UnmarshalText(b []byte) error {
-	if fmtImplicitInt.Match(b) {
-		return p.fromImplicit(b)
+	return p.parse(b, baseOptions())
+}
+
+// detectFormat returns the first syntax that b is recognized as, or FormatUnknown.
+func detectFormat(b []byte) Format {
+	switch {
+	case fmtImplicitInt.Match(b):
+		return FormatImplicitOctal
+	case fmtExplicitInt.Match(b):
+		return FormatExplicitOctal
+	case fmtBasicSingle.Match(b):
+		return FormatBasicSingle
+	case fmtBasicTriple.Match(b):
+		return FormatBasicTriple
+	case fmtSymbolicMatch.Match(b):
+		return FormatSymbolic
+	case fmtFull.Match(b):
+		return FormatFull
+	case fmtShortInt.Match(b):
+		return FormatShortOctal
+	case fmtClassShorthand.Match(b):
+		return FormatClassShorthand
 	}
-	if fmtExplicitInt.Match(b) {
+	return FormatUnknown
+}
+
+func (p *Perm) fromFormat(f Format, b []byte) error {
+	switch f {
+	case FormatImplicitOctal:
+		return p.fromImplicit(b)
+	case FormatExplicitOctal:
 		return p.fromExplicit(b)
-	}
-	if fmtBasicSingle.Match(b) {
+	case FormatBasicSingle:
 		return p.fromBasicSingle(b)
-	}
-	if fmtBasicTriple.Match(b) {
+	case FormatBasicTriple:
 		return p.fromBasicTriple(b)
-	}
-	if fmtSymbolicMatch.Match(b) {
+	case FormatSymbolic:
 		return p.fromSymbolic(b)
-	}
-	if fmtFull.Match(b) {
+	case FormatFull:
 		return p.fromFull(b)
+	case FormatShortOctal:
+		return p.fromShort(b)
+	case FormatChmodOctal:
+		return p.fromChmodOctal(b)
+	case FormatChmodSymbolic:
+		return p.fromChmodSymbolic(b)
+	case FormatClassShorthand:
+		return p.fromClassShorthand(b)
 	}
 	return fmt.Errorf("unrecognized permission syntax %q", b)
 }
@@ -263,9 +321,19 @@ func (p Perm) MarshalText() ([]byte, error) {
 }
 
 // FromString parses the string p following the same rules as UnmarshalText, returning a new Perm. An
-// error is returned if the string cannot be parsed as a Perm value.
-func FromString(p string) (r Perm, err error) {
-	err = r.UnmarshalText([]byte(p))
+// error is returned if the string cannot be parsed as a Perm value. Options may be given to restrict
+// the accepted values for this call only, in addition to those of any default Parser; see Option.
+func FromString(p string, opts ...Option) (r Perm, err error) {
+	if len(opts) == 0 {
+		err = r.UnmarshalText([]byte(p))
+		return
+	}
+	o := *baseOptions()
+	o.cache = nil // results cached by the default Parser do not account for opts
+	for _, opt := range opts {
+		opt(&o)
+	}
+	err = r.parse([]byte(p), &o)
 	return
 }
 
@@ -274,13 +342,64 @@ func (p Perm) String() string {
 	return fs.FileMode(p).String()
 }
 
+// KeyString returns a canonical, minimal representation of a Perm suitable for use as a map key or
+// JSON object key: the entire value in explicit octal form with at least 3 digits, eg `0o644`, or
+// `0o20000000755` for a directory. Every Perm has exactly one KeyString, and it parses back to the
+// same value.
+func (p Perm) KeyString() string {
+	return fmt.Sprintf("0o%03o", uint32(p))
+}
+
+// GoString implements fmt.GoStringer, so that %#v renders p as Go syntax that reads as a
+// permission, eg `posixperm.Perm(0o644)`, rather than as a bare integer.
+func (p Perm) GoString() string {
+	return "posixperm.Perm(" + p.KeyString() + ")"
+}
+
 // FromFileMode returns a new Perm copied from m. It never returns an error.
 func FromFileMode(m fs.FileMode) (r Perm, err error) {
 	r = Perm(m)
 	return
 }
 
+// FromDirEntry returns the mode of the file described by d, as found while reading a directory (eg
+// by fs.WalkDir). The permission bits are not part of a DirEntry, so d.Info is called to get them; if
+// that fails, typically because the file was removed after the directory was read, the error names
+// the entry and wraps the cause, so errors.Is(err, fs.ErrNotExist) still works.
+func FromDirEntry(d fs.DirEntry) (Perm, error) {
+	fi, err := d.Info()
+	if err != nil {
+		return 0, fmt.Errorf("cannot read mode of %s: %w", d.Name(), err)
+	}
+	return Perm(fi.Mode()), nil
+}
+
 // FileMode returns the fs.FileMode typed value of a Perm.
 func (p Perm) FileMode() fs.FileMode {
 	return fs.FileMode(p)
 }
+
+// definedBits are all of the bits that currently have a meaning in fs.FileMode.
+const definedBits = fs.ModeDir | fs.ModeAppend | fs.ModeExclusive | fs.ModeTemporary | fs.ModeSymlink |
+	fs.ModeDevice | fs.ModeNamedPipe | fs.ModeSocket | fs.ModeSetuid | fs.ModeSetgid | fs.ModeCharDevice |
+	fs.ModeSticky | fs.ModeIrregular | fs.ModePerm
+
+// New returns v as a Perm, after checking that it only sets bits defined by fs.FileMode. It is
+// intended for values read from foreign systems or binary formats, which may carry garbage in
+// undefined bit positions that would otherwise pass through silently.
+func New(v uint32) (Perm, error) {
+	if extra := fs.FileMode(v) &^ definedBits; extra != 0 {
+		return 0, fmt.Errorf("value %#o sets undefined mode bits %#o", v, uint32(extra))
+	}
+	return Perm(v), nil
+}
+
+// MustNew is like New but panics if v sets undefined bits. It simplifies initialization of variables
+// holding known-good values.
+func MustNew(v uint32) Perm {
+	p, err := New(v)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
@@ -0,0 +1,97 @@
+package posixperm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// Markdown returns a GitHub flavored Markdown fragment explaining p, for posting in pull request
+// comments and CI reports: the mode in fs.FileMode, octal, and chmod(1) symbolic forms, followed by a
+// table of the rights of each class. File type bits are described but have no octal or symbolic form.
+func (p Perm) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: octal %s", codeSpan(p.String()), codeSpan(octal(p)))
+	if fs.FileMode(p)&^chmodBits == 0 {
+		fmt.Fprintf(&b, ", symbolic %s", codeSpan(p.SymbolicString(true)))
+	} else if t := fs.FileMode(p).Type(); t != 0 {
+		fmt.Fprintf(&b, ", type %s", codeSpan(fileTypeLetters(Perm(t))))
+	}
+	b.WriteString("\n\n| class | read | write | execute | special |\n|---|---|---|---|---|\n")
+	for c := ClassOwner; c <= ClassOther; c++ {
+		a := p.Access(c)
+		special := ""
+		if sp := lsSpecial[c]; fs.FileMode(p)&sp.bit != 0 {
+			special = sp.name
+		}
+		fmt.Fprintf(&b, "| %v | %s | %s | %s | %s |\n", c, yesNo(a.Has(AccessRead)), yesNo(a.Has(AccessWrite)),
+			yesNo(a.Has(AccessExecute)), special)
+	}
+	return b.String()
+}
+
+// WriteMarkdownResults writes a GitHub flavored Markdown report of results, as returned by ApplyExpr,
+// to w: a summary line followed by a table of every path that changed (or for results of a DryRun,
+// would change) or could not be changed. Unchanged paths are counted but not listed.
+func WriteMarkdownResults(w io.Writer, results []ApplyResult) error {
+	var changed, failed int
+	verb := "changed"
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		} else if r.Changed {
+			changed++
+		}
+		if r.DryRun {
+			verb = "would change"
+		}
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "**%d** of %d paths %s", changed, len(results), verb)
+	if failed > 0 {
+		fmt.Fprintf(bw, ", **%d** failed", failed)
+	}
+	fmt.Fprintln(bw)
+	if changed+failed > 0 {
+		fmt.Fprint(bw, "\n| path | before | after | status |\n|---|---|---|---|\n")
+		for _, r := range results {
+			switch {
+			case r.Err != nil:
+				fmt.Fprintf(bw, "| %s | | | %s |\n", tableCell(codeSpan(r.Path)), tableCell(r.Err.Error()))
+			case r.Changed && r.DryRun:
+				fmt.Fprintf(bw, "| %s | %s | %s | would change |\n", tableCell(codeSpan(r.Path)), codeSpan(r.Before.String()),
+					codeSpan(r.After.String()))
+			case r.Changed:
+				fmt.Fprintf(bw, "| %s | %s | %s | changed |\n", tableCell(codeSpan(r.Path)), codeSpan(r.Before.String()),
+					codeSpan(r.After.String()))
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+// codeSpan returns s as a Markdown code span, using a longer fence if s itself contains backticks.
+func codeSpan(s string) string {
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if len(fence) > 1 || strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		return fence + " " + s + " " + fence
+	}
+	return fence + s + fence
+}
+
+// tableCell escapes s for use in a Markdown table cell.
+func tableCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
@@ -0,0 +1,51 @@
+package posixperm
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	want := "`urwxr-x---`: octal `04750`, symbolic `u=rwxs,g=rx,o=`\n\n" +
+		"| class | read | write | execute | special |\n|---|---|---|---|---|\n" +
+		"| owner | yes | yes | yes | setuid |\n" +
+		"| group | yes | no | yes |  |\n" +
+		"| other | no | no | no |  |\n"
+	if s := Perm(fs.ModeSetuid | 0o750).Markdown(); s != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, s)
+	}
+	if s := Perm(fs.ModeDir | 0o755).Markdown(); !strings.HasPrefix(s, "`drwxr-xr-x`: octal `0755`, type `d`\n") {
+		t.Errorf("expected directory to be described, got\n%s", s)
+	}
+}
+
+func TestWriteMarkdownResults(t *testing.T) {
+	results := []ApplyResult{
+		{Path: "a", Before: 0o644, After: 0o644},
+		{Path: "b|c", Before: 0o666, After: 0o644, Changed: true},
+		{Path: "`d`", Err: errors.New("permission denied")},
+	}
+	want := "**1** of 3 paths changed, **1** failed\n\n| path | before | after | status |\n|---|---|---|---|\n" +
+		"| `b\\|c` | `-rw-rw-rw-` | `-rw-r--r--` | changed |\n" +
+		"| `` `d` `` | | | permission denied |\n"
+	var b strings.Builder
+	if err := WriteMarkdownResults(&b, results); err != nil || b.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s (%v)", want, b.String(), err)
+	}
+	b.Reset()
+	if err := WriteMarkdownResults(&b, results[:1]); err != nil || b.String() != "**0** of 1 paths changed\n" {
+		t.Errorf("expected summary only, got %q, %v", b.String(), err)
+	}
+	for i := range results {
+		results[i].DryRun = true
+	}
+	want = "**1** of 3 paths would change, **1** failed\n\n| path | before | after | status |\n|---|---|---|---|\n" +
+		"| `b\\|c` | `-rw-rw-rw-` | `-rw-r--r--` | would change |\n" +
+		"| `` `d` `` | | | permission denied |\n"
+	b.Reset()
+	if err := WriteMarkdownResults(&b, results); err != nil || b.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s (%v)", want, b.String(), err)
+	}
+}
@@ -0,0 +1,96 @@
+package posixperm
+
+import (
+	"strings"
+)
+
+// Matcher is a predicate over modes, such as a policy's constraint on the mode of a path. Matchers are
+// built with AtMost, AtLeast, Exactly, AnyOf, and Not, and combined with And and Or. A Set is also a
+// Matcher.
+type Matcher interface {
+	// Match reports whether p satisfies the constraint.
+	Match(p Perm) bool
+	// String describes the constraint, eg `<=0755`.
+	String() string
+}
+
+// matcher implements Matcher with a function and a description.
+type matcher struct {
+	desc  string
+	match func(Perm) bool
+}
+
+func (m matcher) Match(p Perm) bool { return m.match(p) }
+func (m matcher) String() string    { return m.desc }
+
+// describe renders p for Matcher descriptions, in octal followed by any file type, eg `0755 (type d)`.
+func describe(p Perm) string {
+	if t := fileTypeLetters(p); t != "-" {
+		return octal(p) + " (type " + t + ")"
+	}
+	return octal(p)
+}
+
+// AtMost matches modes that set no bits beyond those of p, so AtMost(0o755) rejects group or other
+// write, and any special or file type bits.
+func AtMost(p Perm) Matcher {
+	return matcher{"<=" + describe(p), func(q Perm) bool { return q&^p == 0 }}
+}
+
+// AtLeast matches modes that set every bit of p, so AtLeast(0o600) requires the owner to have read
+// and write access.
+func AtLeast(p Perm) Matcher {
+	return matcher{">=" + describe(p), func(q Perm) bool { return q&p == p }}
+}
+
+// Exactly matches only p.
+func Exactly(p Perm) Matcher {
+	return matcher{"==" + describe(p), func(q Perm) bool { return q == p }}
+}
+
+// AnyOf matches any of perms. It is equivalent to NewSet(perms...).
+func AnyOf(perms ...Perm) Matcher {
+	return NewSet(perms...)
+}
+
+// Match reports whether p is in s, so that a Set is a Matcher.
+func (s Set) Match(p Perm) bool {
+	return s.Contains(p)
+}
+
+// Not matches modes that m does not.
+func Not(m Matcher) Matcher {
+	return matcher{"!" + m.String(), func(p Perm) bool { return !m.Match(p) }}
+}
+
+// And matches modes that every one of ms matches. With no arguments it matches every mode.
+func And(ms ...Matcher) Matcher {
+	return matcher{joinMatchers(ms, " && "), func(p Perm) bool {
+		for _, m := range ms {
+			if !m.Match(p) {
+				return false
+			}
+		}
+		return true
+	}}
+}
+
+// Or matches modes that any of ms matches. With no arguments it matches no mode.
+func Or(ms ...Matcher) Matcher {
+	return matcher{joinMatchers(ms, " || "), func(p Perm) bool {
+		for _, m := range ms {
+			if m.Match(p) {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+func joinMatchers(ms []Matcher, sep string) string {
+	s := make([]string, len(ms))
+	for i, m := range ms {
+		s[i] = m.String()
+	}
+	return "(" + strings.Join(s, sep) + ")"
+}
@@ -0,0 +1,42 @@
+package posixperm
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestMatchers(t *testing.T) {
+	C := []struct {
+		m    Matcher
+		desc string
+		yes  []Perm
+		no   []Perm
+	}{
+		{AtMost(0o755), "<=0755", []Perm{0o755, 0o644, 0o700, 0}, []Perm{0o775, 0o757, Perm(fs.ModeSetuid | 0o755)}},
+		{AtLeast(0o600), ">=0600", []Perm{0o600, 0o644, 0o777}, []Perm{0o400, 0o200, 0o066}},
+		{Exactly(0o640), "==0640", []Perm{0o640}, []Perm{0o644, Perm(fs.ModeDir | 0o640)}},
+		{AnyOf(0o644, 0o600), "{0600|0644}", []Perm{0o644, 0o600}, []Perm{0o640}},
+		{Not(Exactly(0o777)), "!==0777", []Perm{0o755}, []Perm{0o777}},
+		{And(AtLeast(0o400), AtMost(0o750)), "(>=0400 && <=0750)", []Perm{0o400, 0o750, 0o640}, []Perm{0o644, 0o040}},
+		{Or(Exactly(0o600), AtMost(0o444)), "(==0600 || <=0444)", []Perm{0o600, 0o444, 0o004}, []Perm{0o640}},
+		{And(), "()", []Perm{0o777}, nil},
+		{Or(), "()", nil, []Perm{0}},
+		{AtMost(Perm(fs.ModeSetuid | 0o755)), "<=04755", []Perm{0o755, Perm(fs.ModeSetuid | 0o711)}, []Perm{Perm(fs.ModeSetgid | 0o755)}},
+		{Exactly(Perm(fs.ModeDir | fs.ModeSticky | 0o777)), "==01777 (type d)", []Perm{Perm(fs.ModeDir | fs.ModeSticky | 0o777)}, []Perm{0o1777, 0o777}},
+	}
+	for _, c := range C {
+		if s := c.m.String(); s != c.desc {
+			t.Errorf("expected description %q. got %q", c.desc, s)
+		}
+		for _, p := range c.yes {
+			if !c.m.Match(p) {
+				t.Errorf("with %v, expected %v to match", p, c.m)
+			}
+		}
+		for _, p := range c.no {
+			if c.m.Match(p) {
+				t.Errorf("with %v, expected %v not to match", p, c.m)
+			}
+		}
+	}
+}
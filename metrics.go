@@ -0,0 +1,76 @@
+package posixperm
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// MetricsHook receives an event for every Perm value parsed, successfully or not. Implementations are
+// called synchronously from the parsing goroutine and must be safe for concurrent use; they should
+// return quickly, typically by incrementing a counter in a metrics library.
+type MetricsHook interface {
+	// Parsed is called after a value is successfully parsed in the named syntax (eg "symbolic").
+	Parsed(syntax string)
+	// ParseFailed is called after a value fails to parse. The syntax is "unknown" if the value did
+	// not resemble any supported syntax.
+	ParseFailed(syntax string)
+}
+
+type metricsHookBox struct{ h MetricsHook }
+
+var (
+	metricsHook     atomic.Pointer[metricsHookBox]
+	metricsParsed   [formatCount]atomic.Uint64
+	metricsFailures atomic.Uint64
+)
+
+// SetMetricsHook installs h to receive parse events, replacing any previously installed hook. A nil h
+// removes the hook. The built-in counters reported by Metrics are maintained regardless.
+func SetMetricsHook(h MetricsHook) {
+	if h == nil {
+		metricsHook.Store(nil)
+		return
+	}
+	metricsHook.Store(&metricsHookBox{h})
+}
+
+func recordParse(f Format, err error) {
+	if err != nil {
+		metricsFailures.Add(1)
+	} else {
+		metricsParsed[f].Add(1)
+	}
+	if box := metricsHook.Load(); box != nil {
+		if err != nil {
+			box.h.ParseFailed(formatNames[f])
+		} else {
+			box.h.Parsed(formatNames[f])
+		}
+	}
+}
+
+// MetricsSnapshot is a point-in-time copy of the package's parse counters. Its String method renders
+// it as JSON so that it satisfies expvar.Var.
+type MetricsSnapshot struct {
+	// Parsed counts successfully parsed values, keyed by syntax name (eg "explicit-octal").
+	Parsed map[string]uint64 `json:"parsed"`
+	// Failures counts values that could not be parsed.
+	Failures uint64 `json:"failures"`
+}
+
+// Metrics returns a snapshot of the number of values parsed per syntax and the number of parse
+// failures since the process started.
+func Metrics() MetricsSnapshot {
+	s := MetricsSnapshot{Parsed: make(map[string]uint64, formatCount)}
+	for f := FormatUnknown + 1; f < formatCount; f++ {
+		s.Parsed[formatNames[f]] = metricsParsed[f].Load()
+	}
+	s.Failures = metricsFailures.Load()
+	return s
+}
+
+// String returns the JSON representation of s, satisfying expvar.Var.
+func (s MetricsSnapshot) String() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
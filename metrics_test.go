@@ -0,0 +1,56 @@
+package posixperm
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+type countingHook struct {
+	mu     sync.Mutex
+	parsed map[string]int
+	failed map[string]int
+}
+
+func (h *countingHook) Parsed(syntax string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.parsed[syntax]++
+}
+
+func (h *countingHook) ParseFailed(syntax string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failed[syntax]++
+}
+
+func TestMetrics(t *testing.T) {
+	before := Metrics()
+	h := &countingHook{parsed: map[string]int{}, failed: map[string]int{}}
+	SetMetricsHook(h)
+	defer SetMetricsHook(nil)
+
+	C := []string{"0644", "0o755", "a=rx u+w", "rwx", "-rw-r--r--", "bogus", "047777777777"}
+	for _, c := range C {
+		FromString(c)
+	}
+
+	after := Metrics()
+	if d := after.Parsed["explicit-octal"] - before.Parsed["explicit-octal"]; d < 2 {
+		t.Errorf("expected at least 2 explicit-octal parses, counted %d", d)
+	}
+	if d := after.Failures - before.Failures; d < 2 {
+		t.Errorf("expected at least 2 failures, counted %d", d)
+	}
+	if h.parsed["explicit-octal"] != 2 || h.parsed["symbolic"] != 1 || h.parsed["basic-single"] != 1 || h.parsed["full"] != 1 {
+		t.Errorf("hook saw unexpected parse counts %v", h.parsed)
+	}
+	if h.failed["unknown"] != 1 || h.failed["explicit-octal"] != 1 {
+		t.Errorf("hook saw unexpected failure counts %v", h.failed)
+	}
+
+	var decoded MetricsSnapshot
+	if err := json.Unmarshal([]byte(after.String()), &decoded); err != nil {
+		t.Errorf("snapshot string %q is not valid JSON: %v", after.String(), err)
+	}
+}
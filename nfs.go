@@ -0,0 +1,114 @@
+package posixperm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// the uid and gid that exports(5) maps squashed users to by default
+const nfsNobody = 65534
+
+// ExportOptions holds the permission relevant parts of an NFS export option list, as found in
+// parentheses after a client in /etc/exports, eg `rw,sync,all_squash,anonuid=1000`.
+type ExportOptions struct {
+	// ReadOnly is set for `ro`, the default, and cleared by `rw`.
+	ReadOnly bool
+	// RootSquash maps requests from uid 0 to the anonymous ids. It is the default, cleared by
+	// `no_root_squash`.
+	RootSquash bool
+	// AllSquash maps requests from every uid to the anonymous ids.
+	AllSquash bool
+	// AnonUID and AnonGID are the ids squashed requests are made as, 65534 by default.
+	AnonUID, AnonGID int
+	// Other holds the remaining options, such as `sync` or `sec=krb5p`, in order.
+	Other []string
+}
+
+// ParseExportOptions parses the comma separated NFS export options s, as described in exports(5).
+// Options not affecting permissions are kept in Other without being checked. Where options conflict
+// (eg `ro,rw`), the last one wins, as it does for exportfs.
+func ParseExportOptions(s string) (e ExportOptions, err error) {
+	e = ExportOptions{ReadOnly: true, RootSquash: true, AnonUID: nfsNobody, AnonGID: nfsNobody}
+	if s == "" {
+		return e, nil
+	}
+	for _, opt := range strings.Split(s, ",") {
+		name, value, hasValue := strings.Cut(opt, "=")
+		switch name {
+		case "ro", "rw":
+			e.ReadOnly = name == "ro"
+		case "root_squash", "no_root_squash":
+			e.RootSquash = name == "root_squash"
+		case "all_squash", "no_all_squash":
+			e.AllSquash = name == "all_squash"
+		case "anonuid", "anongid":
+			id, perr := strconv.ParseUint(value, 10, 31)
+			if !hasValue || perr != nil {
+				return e, fmt.Errorf("export option %q requires a numeric id", opt)
+			}
+			if name == "anonuid" {
+				e.AnonUID = int(id)
+			} else {
+				e.AnonGID = int(id)
+			}
+		case "":
+			return e, fmt.Errorf("export options %q contain an empty option", s)
+		default:
+			e.Other = append(e.Other, opt)
+		}
+	}
+	return e, nil
+}
+
+// String returns the export options in the form accepted by ParseExportOptions. The permission
+// relevant options come first, with `ro` or `rw` and the root squashing always explicit, followed by
+// Other.
+func (e ExportOptions) String() string {
+	opts := []string{"rw", "no_root_squash"}
+	if e.ReadOnly {
+		opts[0] = "ro"
+	}
+	if e.RootSquash {
+		opts[1] = "root_squash"
+	}
+	if e.AllSquash {
+		opts = append(opts, "all_squash")
+	}
+	if e.AnonUID != nfsNobody {
+		opts = append(opts, "anonuid="+strconv.Itoa(e.AnonUID))
+	}
+	if e.AnonGID != nfsNobody {
+		opts = append(opts, "anongid="+strconv.Itoa(e.AnonGID))
+	}
+	return strings.Join(append(opts, e.Other...), ",")
+}
+
+// Squash returns the subject the NFS server acts as for requests from client subject s, after root
+// or all squashing. A squashed subject has the anonymous uid and belongs only to the anonymous gid.
+func (e ExportOptions) Squash(s Subject) Subject {
+	if e.AllSquash || e.RootSquash && s.UID == 0 {
+		return Subject{UID: e.AnonUID, GIDs: []int{e.AnonGID}}
+	}
+	return s
+}
+
+// Evaluate is like the package level Evaluate, but for a request made over this export by client
+// subject s: s is squashed first, and write access is refused on a read-only export whatever the
+// mode allows, so that both layers of an NFS share can be reasoned about at once.
+func (e ExportOptions) Evaluate(p Perm, owner, group int, s Subject, want Access) Decision {
+	sq := e.Squash(s)
+	d := Evaluate(p, owner, group, sq, want)
+	if sq.UID != s.UID {
+		d.Reason = fmt.Sprintf("requests from uid %d are squashed to uid %d; %s", s.UID, sq.UID, d.Reason)
+	}
+	if e.ReadOnly && d.Granted.Has(AccessWrite) {
+		d.Granted &^= AccessWrite
+		d.Missing = want & AccessAll &^ d.Granted
+		if d.Missing.Has(AccessWrite) {
+			d.Allowed = false
+			d.Reason += "; but the export is read-only"
+		}
+	}
+	return d
+}
@@ -0,0 +1,72 @@
+package posixperm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExportOptions(t *testing.T) {
+	C := []struct {
+		in   string
+		want ExportOptions
+		out  string
+	}{
+		{"", ExportOptions{ReadOnly: true, RootSquash: true, AnonUID: 65534, AnonGID: 65534}, "ro,root_squash"},
+		{"rw,sync,no_subtree_check", ExportOptions{RootSquash: true, AnonUID: 65534, AnonGID: 65534, Other: []string{"sync", "no_subtree_check"}},
+			"rw,root_squash,sync,no_subtree_check"},
+		{"rw,all_squash,anonuid=1000,anongid=1001", ExportOptions{RootSquash: true, AllSquash: true, AnonUID: 1000, AnonGID: 1001},
+			"rw,root_squash,all_squash,anonuid=1000,anongid=1001"},
+		{"ro,no_root_squash,rw,sec=krb5p", ExportOptions{AnonUID: 65534, AnonGID: 65534, Other: []string{"sec=krb5p"}},
+			"rw,no_root_squash,sec=krb5p"},
+	}
+	for _, c := range C {
+		e, err := ParseExportOptions(c.in)
+		if err != nil {
+			t.Errorf("with %q, got error: %v", c.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(e, c.want) {
+			t.Errorf("with %q, expected %+v. got %+v", c.in, c.want, e)
+		}
+		if s := e.String(); s != c.out {
+			t.Errorf("with %q, expected %q. got %q", c.in, c.out, s)
+		}
+		if f, err := ParseExportOptions(e.String()); err != nil || !reflect.DeepEqual(f, e) {
+			t.Errorf("with %q, %q parsed back to %+v, %v", c.in, e.String(), f, err)
+		}
+	}
+	for _, c := range []string{"anonuid", "anonuid=x", "anongid=-1", "rw,,sync"} {
+		if e, err := ParseExportOptions(c); err == nil {
+			t.Errorf("with %q, expected error, got %+v", c, e)
+		}
+	}
+}
+
+func TestExportOptionsEvaluate(t *testing.T) {
+	rw, _ := ParseExportOptions("rw")
+	ro, _ := ParseExportOptions("ro,no_root_squash")
+	squashAll, _ := ParseExportOptions("rw,all_squash,anonuid=2000,anongid=2000")
+	alice := Subject{UID: 1000, GIDs: []int{1000}}
+	root := Subject{UID: 0, GIDs: []int{0}}
+	C := []struct {
+		e       ExportOptions
+		p       Perm
+		s       Subject
+		want    Access
+		allowed bool
+	}{
+		{rw, 0o644, alice, AccessRead | AccessWrite, true},
+		{ro, 0o644, alice, AccessRead | AccessWrite, false},
+		{ro, 0o644, alice, AccessRead, true},
+		{rw, 0o600, root, AccessRead, false}, // squashed to nobody
+		{ro, 0o600, root, AccessRead, true},
+		{squashAll, 0o600, alice, AccessRead, false},
+		{squashAll, 0o660, Subject{UID: 3000}, AccessWrite, true}, // owned by gid 2000
+	}
+	for i, c := range C {
+		d := c.e.Evaluate(c.p, 1000, 2000, c.s, c.want)
+		if d.Allowed != c.allowed {
+			t.Errorf("at %d with %v over %v, expected allowed %v. got %v", i, c.p, c.e, c.allowed, d)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package posixperm
+
+const raceEnabled = false
@@ -2,8 +2,12 @@ package posixperm
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 type JSONType struct {
@@ -96,6 +100,11 @@ func TestValidSymbolic(t *testing.T) {
 		{`{"P": "u+w u+r u+w"}`, 0o600},
 		{`{"P": "u+wu=ru+wu-r"}`, 0o200},
 		{`{"P": "a=rwx o-r a-w o-x o+r"}`, 0o554},
+		{`{"P": "uugo=rx"}`, 0o555},
+		{`{"P": "ugoa=r"}`, 0o444},
+		{`{"P": "aa=rw"}`, 0o666},
+		{`{"P": "a=rwx ao-w"}`, 0o555},
+		{`{"P": "a=rwx oo-w gu-x"}`, 0o665},
 	}
 	for _, c := range C {
 		d := &JSONType{}
@@ -115,6 +124,8 @@ func TestInvalidSymbolic(t *testing.T) {
 		`{"P": "u=rw o+x m+w"}`,
 		`{"P": "a=rwx o!x"}`,
 		`{"P": "a=rwx g~x"}`,
+		`{"P": "=rwx"}`,
+		`{"P": "ax=r"}`,
 	}
 
 	for _, c := range C {
@@ -262,3 +273,105 @@ func TestValidFullRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func TestNew(t *testing.T) {
+	C := []struct {
+		v  uint32
+		ok bool
+	}{
+		{0o644, true},
+		{0o777, true},
+		{uint32(fs.ModeDir | fs.ModeSetgid | 0o775), true},
+		{uint32(definedBits), true},
+		{0o1000, false},
+		{0o4755, false},
+		{1 << 18, false},
+	}
+	for _, c := range C {
+		p, err := New(c.v)
+		if c.ok && (err != nil || p != Perm(c.v)) {
+			t.Errorf("with %#o, expected %v. got %v, %v", c.v, Perm(c.v), p, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %#o, got nil error", c.v)
+		}
+	}
+}
+
+func TestMustNewPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected MustNew to panic on undefined bits")
+		}
+	}()
+	MustNew(0o4755)
+}
+
+func TestKeyString(t *testing.T) {
+	C := []struct {
+		p Perm
+		s string
+	}{
+		{0o644, "0o644"},
+		{0o000, "0o000"},
+		{0o007, "0o007"},
+		{Perm(fs.ModeDir | 0o755), "0o20000000755"},
+		{Perm(fs.ModeSetuid | 0o755), "0o40000755"},
+	}
+	for _, c := range C {
+		if s := c.p.KeyString(); s != c.s {
+			t.Errorf("with %v, expected %q. got %q", c.p, c.s, s)
+		}
+		p, err := FromString(c.p.KeyString())
+		if err != nil || p != c.p {
+			t.Errorf("with %v, KeyString %q parsed back to %v, %v", c.p, c.p.KeyString(), p, err)
+		}
+	}
+}
+
+func TestGoString(t *testing.T) {
+	C := []struct {
+		v    any
+		want string
+	}{
+		{Perm(0o644), "posixperm.Perm(0o644)"},
+		{Perm(fs.ModeDir | 0o755), "posixperm.Perm(0o20000000755)"},
+		{[]Perm{0o600}, "[]posixperm.Perm{posixperm.Perm(0o600)}"},
+	}
+	for _, c := range C {
+		if got := fmt.Sprintf("%#v", c.v); got != c.want {
+			t.Errorf("with %v, expected %q. got %q", c.v, c.want, got)
+		}
+	}
+}
+
+// goneEntry is a DirEntry for a file that was removed after its directory was read.
+type goneEntry struct{ fs.DirEntry }
+
+func (goneEntry) Name() string               { return "gone" }
+func (goneEntry) Info() (fs.FileInfo, error) { return nil, fs.ErrNotExist }
+
+func TestFromDirEntry(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a":   {Mode: 0o640},
+		"d":   {Mode: fs.ModeDir | 0o750},
+		"d/x": {Mode: fs.ModeSetuid | 0o755},
+	}
+	want := map[string]Perm{".": Perm(fs.ModeDir | 0o555), "a": 0o640, "d": Perm(fs.ModeDir | 0o750), "d/x": Perm(fs.ModeSetuid | 0o755)}
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		p, err := FromDirEntry(d)
+		if err != nil || p != want[path] {
+			t.Errorf("with %q, expected %v. got %v, %v", path, want[path], p, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromDirEntry(goneEntry{}); !errors.Is(err, fs.ErrNotExist) || !strings.Contains(err.Error(), "gone") {
+		t.Errorf("expected wrapped fs.ErrNotExist naming the entry, got %v", err)
+	}
+}
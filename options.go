@@ -0,0 +1,235 @@
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"sync/atomic"
+	"unicode"
+)
+
+// Option restricts or extends the values accepted when parsing a Perm. Options are passed to
+// FromString for one-off validation, or to NewParser to build a reusable Parser.
+type Option func(*options)
+
+type options struct {
+	strictOctal bool
+	lenient     bool
+	unambiguous bool
+	compat      compat
+	noSpecial   bool
+	hasMax      bool
+	max         Perm
+	jsonNumbers JSONNumbers
+	hasMaxLen   bool
+	maxLen      int
+	cache       *parseCache
+
+	continueOnError bool
+}
+
+// defaultParser, if set, supplies the options used by UnmarshalText.
+var defaultParser atomic.Pointer[Parser]
+
+// noOptions are used by UnmarshalText if no default Parser has been set.
+var noOptions options
+
+// baseOptions returns the options of the default Parser, or the zero options if none is set.
+func baseOptions() *options {
+	if ps := defaultParser.Load(); ps != nil {
+		return &ps.opts
+	}
+	return &noOptions
+}
+
+// SetDefaultParser installs ps as the process-wide default, whose Options are then applied by
+// UnmarshalText (and so by every Perm unmarshaled from JSON, YAML, etc) and FromString. It may only be
+// called once, typically during program initialization; later calls return an error and leave the
+// default unchanged. It is safe to call concurrently with parsing.
+func SetDefaultParser(ps *Parser) error {
+	if ps == nil {
+		return errors.New("cannot set a nil default Parser")
+	}
+	if !defaultParser.CompareAndSwap(nil, ps) {
+		return errors.New("default Parser has already been set")
+	}
+	return nil
+}
+
+// WithStrictOctal rejects numeric values lacking an explicit `0` or `0o` prefix, such as `644`, which
+// are otherwise interpreted as octal.
+func WithStrictOctal() Option {
+	return func(o *options) { o.strictOctal = true }
+}
+
+// WithLenient accepts syntax that other tools tolerate but that is not accepted by default, such as 1
+// and 2 digit octal values (`7` is 0007 and `75` is 0075, as with chmod). Symbolic clauses may also be
+// separated by commas as well as spaces, and empty clauses left behind by concatenating fragments
+// (eg `,u=rw,,g=r,`) are ignored.
+func WithLenient() Option {
+	return func(o *options) { o.lenient = true }
+}
+
+// WithRejectAmbiguous rejects bare numbers whose octal and decimal readings differ, such as `644` or
+// `0644` (but not `7`, or `0o644` which is unambiguously octal). This guards fields that users may
+// expect to be decimal; see AmbiguityReport.
+func WithRejectAmbiguous() Option {
+	return func(o *options) { o.unambiguous = true }
+}
+
+// WithNoSpecialBits rejects values that set anything other than the 9 permission bits, such as setuid,
+// sticky, or file type bits.
+func WithNoSpecialBits() Option {
+	return func(o *options) { o.noSpecial = true }
+}
+
+// WithMaxMode rejects values that set any bit not also set in max. For example, WithMaxMode(0o755)
+// rejects group or other write permission. If a maximum is already in effect, the two are combined.
+func WithMaxMode(max Perm) Option {
+	return func(o *options) {
+		if o.hasMax {
+			max &= o.max
+		}
+		o.hasMax = true
+		o.max = max
+	}
+}
+
+// DefaultMaxLength is the length in bytes beyond which input is rejected without being examined,
+// unless changed with WithMaxLength. It is far longer than any reasonable permission expression.
+const DefaultMaxLength = 1024
+
+// LengthError is returned for input longer than the maximum length; see WithMaxLength.
+type LengthError struct {
+	Length, Max int
+}
+
+func (e *LengthError) Error() string {
+	return fmt.Sprintf("permission expression of %d bytes exceeds maximum length of %d", e.Length, e.Max)
+}
+
+// WithMaxLength rejects input longer than n bytes with a *LengthError before any other processing,
+// bounding the cost of parsing untrusted values. If n is 0 or less, there is no limit. Without this
+// option the limit is DefaultMaxLength.
+func WithMaxLength(n int) Option {
+	return func(o *options) {
+		o.hasMaxLen = true
+		o.maxLen = n
+	}
+}
+
+// Parser parses permission expressions with a fixed set of Options. It is safe for concurrent use.
+//
+// Parsing is intended to be safe on untrusted input. Its cost is linear in the length of the input,
+// which is itself bounded (see WithMaxLength), whatever the input contains: syntaxes are recognized
+// with Go's regexp package, which guarantees linear time matching without backtracking, the symbolic
+// and full forms are then decoded by hand-written single pass scanners, and chmod(1) expressions have
+// a scanner of their own that likewise does a constant amount of work per byte.
+// TestParseAdversarialAllocs checks that allocations per byte stay flat as crafted inputs grow, and
+// the benchmarks named BenchmarkParseAdversarial measure the time per byte on a given platform.
+type Parser struct {
+	opts options
+}
+
+// NewParser returns a Parser applying opts to every value it parses.
+func NewParser(opts ...Option) *Parser {
+	ps := &Parser{}
+	for _, opt := range opts {
+		opt(&ps.opts)
+	}
+	return ps
+}
+
+// Parse parses s following the same rules as UnmarshalText, subject to the Parser's Options.
+func (ps *Parser) Parse(s string) (r Perm, err error) {
+	err = r.parse([]byte(s), &ps.opts)
+	return
+}
+
+// parse detects the syntax of b and parses it subject to o, recording the outcome in the metrics.
+func (p *Perm) parse(b []byte, o *options) error {
+	if o.cache != nil {
+		if e, ok := o.cache.get(b); ok {
+			recordParse(e.f, nil)
+			*p = e.p
+			return nil
+		}
+	}
+	if err := o.allowLength(b); err != nil {
+		recordParse(FormatUnknown, err)
+		return err
+	}
+	raw := b
+	if o.lenient {
+		b = tidySeparators(b, o.compat != compatNone)
+	}
+	f := detectFormat(b)
+	if o.compat != compatNone {
+		f = detectCompatFormat(o.compat, b)
+	}
+	err := o.allowFormat(f, b)
+	var r Perm
+	if err == nil {
+		err = r.fromFormat(f, b)
+	}
+	if err == nil {
+		err = o.allowValue(r)
+	}
+	recordParse(f, err)
+	if err == nil {
+		*p = r
+		if o.cache != nil {
+			o.cache.put(raw, cacheEntry{r, f})
+		}
+	}
+	return err
+}
+
+// tidySeparators drops empty clauses from b, along with leading and trailing separators. Clauses of
+// chmod(1) modes are rejoined with commas; otherwise commas and runs of spaces become a single space.
+func tidySeparators(b []byte, chmod bool) []byte {
+	sep := func(r rune) bool { return r == ',' || unicode.IsSpace(r) }
+	join := " "
+	if chmod {
+		sep = func(r rune) bool { return r == ',' }
+		join = ","
+	}
+	return []byte(strings.Join(strings.FieldsFunc(string(b), sep), join))
+}
+
+func (o *options) allowLength(b []byte) error {
+	max := DefaultMaxLength
+	if o.hasMaxLen {
+		max = o.maxLen
+	}
+	if max > 0 && len(b) > max {
+		return &LengthError{Length: len(b), Max: max}
+	}
+	return nil
+}
+
+func (o *options) allowFormat(f Format, b []byte) error {
+	if o.strictOctal && (f == FormatImplicitOctal || (f == FormatShortOctal || f == FormatChmodOctal) && b[0] != '0') {
+		return fmt.Errorf("octal permission value %q lacks an explicit 0 or 0o prefix", b)
+	}
+	if o.unambiguous {
+		if err := ambiguityError(b); err != nil {
+			return err
+		}
+	}
+	if !o.lenient && f == FormatShortOctal {
+		return fmt.Errorf("short octal permission value %q is only accepted with lenient parsing", b)
+	}
+	return nil
+}
+
+func (o *options) allowValue(p Perm) error {
+	if o.noSpecial && fs.FileMode(p)&^fs.ModePerm != 0 {
+		return fmt.Errorf("permission %v sets bits other than permission bits", p)
+	}
+	if o.hasMax && p&^o.max != 0 {
+		return fmt.Errorf("permission %v exceeds maximum %v", p, o.max)
+	}
+	return nil
+}
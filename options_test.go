@@ -0,0 +1,202 @@
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestValidOptions(t *testing.T) {
+	C := []struct {
+		s    string
+		opts []Option
+		v    Perm
+	}{
+		{"0644", []Option{WithStrictOctal()}, 0o644},
+		{"0o644", []Option{WithStrictOctal()}, 0o644},
+		{"a=rx u+w", []Option{WithStrictOctal(), WithNoSpecialBits()}, 0o755},
+		{"0755", []Option{WithMaxMode(0o755)}, 0o755},
+		{"0640", []Option{WithMaxMode(0o755)}, 0o640},
+		{"urwxr-xr-x", []Option{WithMaxMode(Perm(fs.ModeSetuid | 0o755))}, Perm(fs.ModeSetuid | 0o755)},
+		{"7", []Option{WithLenient()}, 0o007},
+		{"75", []Option{WithLenient()}, 0o075},
+		{"0", []Option{WithLenient()}, 0o000},
+		{"00", []Option{WithLenient()}, 0o000},
+		{"0o5", []Option{WithLenient()}, 0o005},
+		{"0755", []Option{WithLenient()}, 0o755},
+		{",u=rw,,g=r,", []Option{WithLenient()}, 0o640},
+		{"a=rx,  u+w ", []Option{WithLenient()}, 0o755},
+		{"u:rw,,g:r", []Option{WithLenient()}, 0o640},
+		{",u=rw,,g=r,o=,", []Option{WithLenient(), WithAnsible()}, 0o640},
+	}
+	for _, c := range C {
+		p, err := FromString(c.s, c.opts...)
+		if err != nil {
+			t.Errorf("with %q, expected %04O. got error: %v", c.s, c.v, err)
+		}
+		if p != c.v {
+			t.Errorf("with %q, expected %04O. got %04O", c.s, c.v, p)
+		}
+		p, err = NewParser(c.opts...).Parse(c.s)
+		if err != nil || p != c.v {
+			t.Errorf("with Parser and %q, expected %04O. got %04O, %v", c.s, c.v, p, err)
+		}
+	}
+}
+
+func TestInvalidOptions(t *testing.T) {
+	C := []struct {
+		s    string
+		opts []Option
+	}{
+		{"644", []Option{WithStrictOctal()}},
+		{"urwxr-xr-x", []Option{WithNoSpecialBits()}},
+		{"drwxr-xr-x", []Option{WithNoSpecialBits()}},
+		{"0775", []Option{WithMaxMode(0o755)}},
+		{"a=rwx", []Option{WithMaxMode(0o755)}},
+		{"0999", []Option{WithMaxMode(0o777)}},
+		{"7", nil},
+		{"75", nil},
+		{"0", nil},
+		{"8", []Option{WithLenient()}},
+		{"0o", []Option{WithLenient()}},
+		{"75", []Option{WithLenient(), WithMaxMode(0o070)}},
+		{"75", []Option{WithLenient(), WithStrictOctal()}},
+		{",u=rw,,g=r,", nil},
+		{",u=rw,,g=r,", []Option{WithAnsible()}},
+		{",,", []Option{WithLenient()}},
+		{"u=rw g=r", []Option{WithLenient(), WithAnsible()}},
+	}
+	for _, c := range C {
+		p, err := FromString(c.s, c.opts...)
+		if err == nil {
+			t.Errorf("got nil error for %q, parsed to %04O", c.s, p)
+		}
+		p, err = NewParser(c.opts...).Parse(c.s)
+		if err == nil {
+			t.Errorf("got nil error from Parser for %q, parsed to %04O", c.s, p)
+		}
+	}
+}
+
+func TestOptionsDoNotLeak(t *testing.T) {
+	if _, err := FromString("644", WithStrictOctal()); err == nil {
+		t.Fatalf("expected WithStrictOctal to reject 644")
+	}
+	if _, err := FromString("644"); err != nil {
+		t.Errorf("per-call option leaked into later call: %v", err)
+	}
+}
+
+func TestDefaultParser(t *testing.T) {
+	defer defaultParser.Store(nil)
+
+	if err := SetDefaultParser(nil); err == nil {
+		t.Errorf("expected error setting a nil default Parser")
+	}
+	if err := SetDefaultParser(NewParser(WithStrictOctal(), WithMaxMode(0o755))); err != nil {
+		t.Fatalf("got error setting default Parser: %v", err)
+	}
+	if err := SetDefaultParser(NewParser()); err == nil {
+		t.Errorf("expected error setting the default Parser twice")
+	}
+
+	d := &JSONType{}
+	if err := d.P.UnmarshalText([]byte("644")); err == nil {
+		t.Errorf("default Parser did not reject implicit octal in UnmarshalText")
+	}
+	if _, err := FromString("0775"); err == nil {
+		t.Errorf("default Parser did not reject 0775 in FromString")
+	}
+	if _, err := FromString("0750", WithMaxMode(0o700)); err == nil {
+		t.Errorf("per-call maximum was not combined with default maximum")
+	}
+	if p, err := FromString("0o755"); err != nil || p != 0o755 {
+		t.Errorf("expected 0755 under default Parser, got %04O, %v", p, err)
+	}
+}
+
+func TestMaxLength(t *testing.T) {
+	long := strings.Repeat("u+r ", 300) + "u+r"
+	var le *LengthError
+	if _, err := FromString(long); !errors.As(err, &le) || le.Length != len(long) || le.Max != DefaultMaxLength {
+		t.Errorf("expected LengthError for %d bytes, got %v", len(long), err)
+	}
+	if p, err := FromString(long, WithMaxLength(0)); err != nil || p != 0o400 {
+		t.Errorf("expected no limit with WithMaxLength(0), got %v, %v", p, err)
+	}
+	if _, err := FromString("a=rwx o-w", WithMaxLength(8)); !errors.As(err, &le) || le.Max != 8 {
+		t.Errorf("expected LengthError with WithMaxLength(8), got %v", err)
+	}
+	if p, err := NewParser(WithMaxLength(9)).Parse("a=rwx o-w"); err != nil || p != 0o775 {
+		t.Errorf("expected input at the limit to be accepted, got %v, %v", p, err)
+	}
+}
+
+// adversarialInputs generate inputs of length n crafted to be expensive for a backtracking parser.
+var adversarialInputs = map[string]func(n int) string{
+	// almost symbolic, failing only at the final byte
+	"symbolic": func(n int) string { return strings.Repeat("ug+rwx ", n/7) + "!" },
+	// almost a chmod expression, in compatibility mode
+	"chmod": func(n int) string { return strings.Repeat("u+rwX,", n/6) + "," },
+	// long runs of separators for lenient parsing
+	"separators": func(n int) string { return "u=rw" + strings.Repeat(", ", n/2) + "q" },
+	// long numbers
+	"octal": func(n int) string { return "0" + strings.Repeat("7", n-1) },
+}
+
+var adversarialLengths = []int{64, 256, 1024}
+
+// adversarialParser returns the Parser that reaches the expensive paths for the named input, with no
+// length limit so that every input is parsed in full.
+func adversarialParser(name string) *Parser {
+	if name == "chmod" {
+		return NewParser(WithAnsible(), WithMaxLength(0))
+	}
+	return NewParser(WithLenient(), WithMaxLength(0))
+}
+
+func TestParseAdversarialAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("the race detector allocates")
+	}
+	for name, gen := range adversarialInputs {
+		ps := adversarialParser(name)
+		var allocs, perByte []float64
+		for _, n := range adversarialLengths {
+			s := gen(n)
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			allocs = append(allocs, testing.AllocsPerRun(100, func() { ps.Parse(s) }))
+			runtime.ReadMemStats(&after)
+			perByte = append(perByte, float64(after.TotalAlloc-before.TotalAlloc)/101/float64(len(s)))
+		}
+		for i := range adversarialLengths[1:] {
+			if allocs[i+1] > allocs[0]+8 || perByte[i+1] > 4*perByte[0]+1 {
+				t.Errorf("with %s, expected allocations to stay flat, got %v allocations and %v bytes per input byte at lengths %v",
+					name, allocs, perByte, adversarialLengths)
+				break
+			}
+		}
+	}
+}
+
+// BenchmarkParseAdversarial parses adversarialInputs at increasing lengths; the time per byte should
+// not grow with the length.
+func BenchmarkParseAdversarial(b *testing.B) {
+	for name, gen := range adversarialInputs {
+		for _, n := range adversarialLengths {
+			s := gen(n)
+			ps := adversarialParser(name)
+			b.Run(fmt.Sprintf("%s/%d", name, n), func(b *testing.B) {
+				b.SetBytes(int64(len(s)))
+				for i := 0; i < b.N; i++ {
+					ps.Parse(s)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !unix
+
+package posixperm
+
+import "io/fs"
+
+// fileOwner returns the numeric owner and group of fi, if the platform provides them.
+func fileOwner(fi fs.FileInfo) (uid, gid int, ok bool) {
+	return -1, -1, false
+}
+
+// fileDevice returns the id of the device holding fi, if the platform provides it.
+func fileDevice(fi fs.FileInfo) (dev uint64, ok bool) {
+	return 0, false
+}
+
+// fileLinks returns the device and inode identifying the file behind fi, and its number of hard
+// links, if the platform provides them.
+func fileLinks(fi fs.FileInfo) (id [2]uint64, nlink uint64, ok bool) {
+	return id, 0, false
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package posixperm
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileOwner returns the numeric owner and group of fi, if the platform provides them.
+func fileOwner(fi fs.FileInfo) (uid, gid int, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return -1, -1, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
+
+// fileDevice returns the id of the device holding fi, if the platform provides it.
+func fileDevice(fi fs.FileInfo) (dev uint64, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}
+
+// fileLinks returns the device and inode identifying the file behind fi, and its number of hard
+// links, if the platform provides them.
+func fileLinks(fi fs.FileInfo) (id [2]uint64, nlink uint64, ok bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return id, 0, false
+	}
+	return [2]uint64{uint64(st.Dev), uint64(st.Ino)}, uint64(st.Nlink), true
+}
@@ -0,0 +1,138 @@
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"os/user"
+	"regexp"
+	"strconv"
+)
+
+// a chown style "user", "user:group", "user:", or ":group" expression
+var fmtOwnership = regexp.MustCompile(`^([^:\s]*)(:([^:\s]*))?$`)
+
+// Ownership represents a chown-style owner specification, as is commonly found next to a Perm in
+// configuration files. Either of User or Group may be empty, meaning that part of the ownership is
+// left unchanged. Each may hold a name or a numeric id; numeric values are used as-is without
+// consulting the user database.
+type Ownership struct {
+	User  string
+	Group string
+	// LoginGroup is set for the "user:" form, which like chown selects the login group of User.
+	LoginGroup bool
+}
+
+// ParseOwnership parses s in one of the forms accepted by chown: `user`, `user:group`, `user:`,
+// `:group`, or the numeric equivalents such as `0:0`. An error is returned if s is empty or
+// otherwise malformed.
+func ParseOwnership(s string) (o Ownership, err error) {
+	err = o.UnmarshalText([]byte(s))
+	return
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for this type, following the rules of
+// ParseOwnership.
+func (o *Ownership) UnmarshalText(b []byte) error {
+	m := fmtOwnership.FindSubmatch(b)
+	if m == nil {
+		return fmt.Errorf("unrecognized ownership syntax %q", b)
+	}
+	r := Ownership{User: string(m[1]), Group: string(m[3])}
+	if len(m[2]) > 0 && r.Group == "" {
+		if r.User == "" {
+			return fmt.Errorf("ownership %q specifies neither user nor group", b)
+		}
+		r.LoginGroup = true
+	}
+	if r.User == "" && r.Group == "" {
+		return fmt.Errorf("ownership %q specifies neither user nor group", b)
+	}
+	*o = r
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler for this type. It returns the String() representation.
+func (o Ownership) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// String returns the chown-style representation of an Ownership.
+func (o Ownership) String() string {
+	if o.Group != "" {
+		return o.User + ":" + o.Group
+	}
+	if o.LoginGroup {
+		return o.User + ":"
+	}
+	return o.User
+}
+
+// UID returns the numeric user id of o, consulting the user database if User is a name. If User is
+// empty, -1 is returned, which os.Chown interprets as "leave unchanged".
+func (o Ownership) UID() (int, error) {
+	if o.User == "" {
+		return -1, nil
+	}
+	if id, err := strconv.ParseUint(o.User, 10, 31); err == nil {
+		return int(id), nil
+	}
+	u, err := user.Lookup(o.User)
+	if err != nil {
+		return -1, fmt.Errorf("cannot resolve user %q: %w", o.User, err)
+	}
+	return strconv.Atoi(u.Uid)
+}
+
+// GID returns the numeric group id of o, consulting the group database if Group is a name, or the
+// user database if LoginGroup is set. If no group is specified, -1 is returned, which os.Chown
+// interprets as "leave unchanged".
+func (o Ownership) GID() (int, error) {
+	if o.Group == "" && o.LoginGroup {
+		u, err := user.Lookup(o.User)
+		if err != nil {
+			u, err = user.LookupId(o.User)
+		}
+		if err != nil {
+			return -1, fmt.Errorf("cannot resolve login group of user %q: %w", o.User, err)
+		}
+		return strconv.Atoi(u.Gid)
+	}
+	if o.Group == "" {
+		return -1, nil
+	}
+	if id, err := strconv.ParseUint(o.Group, 10, 31); err == nil {
+		return int(id), nil
+	}
+	g, err := user.LookupGroup(o.Group)
+	if err != nil {
+		return -1, fmt.Errorf("cannot resolve group %q: %w", o.Group, err)
+	}
+	return strconv.Atoi(g.Gid)
+}
+
+// Resolve returns the numeric user and group ids of o. See UID and GID.
+func (o Ownership) Resolve() (uid, gid int, err error) {
+	uid, err = o.UID()
+	if err != nil {
+		return
+	}
+	gid, err = o.GID()
+	return
+}
+
+// Apply resolves o and changes the ownership of the named file accordingly. If the file is a
+// symbolic link, it changes the ownership of the link's target.
+func (o Ownership) Apply(path string) error {
+	return o.apply(hostPaths{}, path)
+}
+
+func (o Ownership) apply(m mutator, path string) error {
+	uid, gid, err := o.Resolve()
+	if err != nil {
+		return err
+	}
+	if uid == -1 && gid == -1 {
+		return errors.New("ownership specifies neither user nor group")
+	}
+	return m.Chown(path, uid, gid)
+}
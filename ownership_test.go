@@ -0,0 +1,109 @@
+package posixperm
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+type JSONOwnershipType struct {
+	O Ownership
+}
+
+func TestValidOwnership(t *testing.T) {
+	C := []struct {
+		s string
+		v Ownership
+	}{
+		{"root", Ownership{User: "root"}},
+		{"root:wheel", Ownership{User: "root", Group: "wheel"}},
+		{"app:", Ownership{User: "app", LoginGroup: true}},
+		{":staff", Ownership{Group: "staff"}},
+		{"0:0", Ownership{User: "0", Group: "0"}},
+		{"1000", Ownership{User: "1000"}},
+	}
+	for _, c := range C {
+		o, err := ParseOwnership(c.s)
+		if err != nil {
+			t.Errorf("with %q, expected %+v. got error: %v", c.s, c.v, err)
+		}
+		if o != c.v {
+			t.Errorf("with %q, expected %+v. got %+v", c.s, c.v, o)
+		}
+		if o.String() != c.s {
+			t.Errorf("with %q, got non-canonical string %q", c.s, o.String())
+		}
+	}
+}
+
+func TestInvalidOwnership(t *testing.T) {
+	C := []string{
+		"",
+		":",
+		"a:b:c",
+		"root :wheel",
+		"ro ot",
+	}
+	for _, c := range C {
+		o, err := ParseOwnership(c)
+		if err == nil {
+			t.Errorf("got nil error for %q, parsed to %+v", c, o)
+		}
+	}
+}
+
+func TestOwnershipRoundTrip(t *testing.T) {
+	C := []string{
+		`{"O":"root:wheel"}`,
+		`{"O":"app:"}`,
+		`{"O":":0"}`,
+	}
+	for _, c := range C {
+		d := &JSONOwnershipType{}
+		err := json.Unmarshal([]byte(c), d)
+		if err != nil {
+			t.Errorf("with %q, got unmarshal error: %v", c, err)
+		}
+		e, err := json.Marshal(d)
+		if err != nil {
+			t.Errorf("with %q, got re-marshal error: %v", c, err)
+		}
+		if string(e) != c {
+			t.Errorf("with %q, had intermediate %+v, but got %q", c, d, e)
+		}
+	}
+}
+
+func TestOwnershipResolveNumeric(t *testing.T) {
+	uid, gid, err := Ownership{User: "123", Group: "456"}.Resolve()
+	if err != nil {
+		t.Fatalf("got error resolving numeric ownership: %v", err)
+	}
+	if uid != 123 || gid != 456 {
+		t.Errorf("expected 123:456, got %d:%d", uid, gid)
+	}
+	uid, gid, err = Ownership{Group: "456"}.Resolve()
+	if err != nil {
+		t.Fatalf("got error resolving group-only ownership: %v", err)
+	}
+	if uid != -1 || gid != 456 {
+		t.Errorf("expected -1:456, got %d:%d", uid, gid)
+	}
+}
+
+func TestOwnershipApply(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("chown is not supported on", runtime.GOOS)
+	}
+	path := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o := Ownership{User: strconv.Itoa(os.Getuid()), Group: strconv.Itoa(os.Getgid())}
+	if err := o.Apply(path); err != nil {
+		t.Errorf("got error applying %v to own file: %v", o, err)
+	}
+}
@@ -0,0 +1,59 @@
+package posixperm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// AppendPacked appends perms to dst as fixed width 4 byte little endian values and returns the
+// extended buffer. The encoding is suited to snapshot files and caches that need random access to
+// the mode of the nth entry; see DecodePacked.
+func AppendPacked(dst []byte, perms []Perm) []byte {
+	for _, p := range perms {
+		dst = binary.LittleEndian.AppendUint32(dst, uint32(p))
+	}
+	return dst
+}
+
+// DecodePacked decodes values written by AppendPacked. An error is returned if b is not a whole
+// number of values.
+func DecodePacked(b []byte) ([]Perm, error) {
+	if len(b)%4 != 0 {
+		return nil, fmt.Errorf("packed permissions have %d trailing bytes", len(b)%4)
+	}
+	perms := make([]Perm, len(b)/4)
+	for i := range perms {
+		perms[i] = Perm(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return perms, nil
+}
+
+// AppendVarint appends perms to dst as a stream of unsigned varints (see encoding/binary) and returns
+// the extended buffer. Plain permission bits take 1 or 2 bytes each, so this is usually the smaller
+// encoding, but values with file type bits such as fs.ModeDir take 5. See DecodeVarint.
+func AppendVarint(dst []byte, perms []Perm) []byte {
+	for _, p := range perms {
+		dst = binary.AppendUvarint(dst, uint64(p))
+	}
+	return dst
+}
+
+// DecodeVarint decodes values written by AppendVarint. An error is returned if the stream is
+// truncated or holds a value that does not fit in a Perm.
+func DecodeVarint(b []byte) ([]Perm, error) {
+	var perms []Perm
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		if n == 0 {
+			return perms, errors.New("truncated varint permission stream")
+		}
+		if n < 0 || v > math.MaxUint32 {
+			return perms, fmt.Errorf("varint permission stream holds an out of range value after %d values", len(perms))
+		}
+		perms = append(perms, Perm(v))
+		b = b[n:]
+	}
+	return perms, nil
+}
@@ -0,0 +1,112 @@
+package posixperm
+
+import (
+	"encoding/binary"
+	"io/fs"
+	"math/rand"
+	"testing"
+)
+
+func TestPackedRoundTrip(t *testing.T) {
+	P := []Perm{0, 0o644, 0o755, Perm(fs.ModeDir | 0o750), Perm(fs.ModeSetuid | fs.ModeSymlink | 0o777), 0xffffffff}
+	for _, enc := range []struct {
+		name   string
+		append func([]byte, []Perm) []byte
+		decode func([]byte) ([]Perm, error)
+	}{{"packed", AppendPacked, DecodePacked}, {"varint", AppendVarint, DecodeVarint}} {
+		b := enc.append([]byte("x"), P)
+		if b[0] != 'x' {
+			t.Errorf("with %s, expected prefix to be kept", enc.name)
+		}
+		got, err := enc.decode(b[1:])
+		if err != nil || len(got) != len(P) {
+			t.Errorf("with %s, expected %v. got %v, %v", enc.name, P, got, err)
+			continue
+		}
+		for i := range P {
+			if got[i] != P[i] {
+				t.Errorf("with %s at %d, expected %v. got %v", enc.name, i, P[i], got[i])
+			}
+		}
+		if got, err := enc.decode(nil); err != nil || len(got) != 0 {
+			t.Errorf("with %s and no input, expected no values. got %v, %v", enc.name, got, err)
+		}
+	}
+	if n := len(AppendVarint(nil, []Perm{0o644, 0o7})); n != 3 {
+		t.Errorf("expected 3 varint bytes, got %d", n)
+	}
+}
+
+func TestInvalidPacked(t *testing.T) {
+	if _, err := DecodePacked([]byte{1, 2, 3, 4, 5}); err == nil {
+		t.Errorf("expected error for trailing bytes")
+	}
+	if _, err := DecodeVarint([]byte{0x80}); err == nil {
+		t.Errorf("expected error for truncated varint")
+	}
+	if _, err := DecodeVarint(binary.AppendUvarint(nil, 1<<32)); err == nil {
+		t.Errorf("expected error for out of range varint")
+	}
+}
+
+// benchPerms returns a mix of modes typical of a filesystem scan.
+func benchPerms(n int) []Perm {
+	common := []Perm{0o644, 0o644, 0o644, 0o755, 0o600, Perm(fs.ModeDir | 0o755), Perm(fs.ModeSymlink | 0o777)}
+	r := rand.New(rand.NewSource(1))
+	perms := make([]Perm, n)
+	for i := range perms {
+		perms[i] = common[r.Intn(len(common))]
+	}
+	return perms
+}
+
+func BenchmarkAppendPacked(b *testing.B) {
+	perms := benchPerms(100000)
+	buf := make([]byte, 0, 4*len(perms))
+	b.SetBytes(int64(len(perms)))
+	for i := 0; i < b.N; i++ {
+		buf = AppendPacked(buf[:0], perms)
+	}
+}
+
+func BenchmarkDecodePacked(b *testing.B) {
+	buf := AppendPacked(nil, benchPerms(100000))
+	b.SetBytes(int64(len(buf) / 4))
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodePacked(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendVarint(b *testing.B) {
+	perms := benchPerms(100000)
+	buf := make([]byte, 0, 5*len(perms))
+	b.SetBytes(int64(len(perms)))
+	for i := 0; i < b.N; i++ {
+		buf = AppendVarint(buf[:0], perms)
+	}
+}
+
+func BenchmarkDecodeVarint(b *testing.B) {
+	perms := benchPerms(100000)
+	buf := AppendVarint(nil, perms)
+	b.SetBytes(int64(len(perms)))
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeVarint(buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshalTextSlice(b *testing.B) {
+	perms := benchPerms(100000)
+	b.SetBytes(int64(len(perms)))
+	for i := 0; i < b.N; i++ {
+		for _, p := range perms {
+			if _, err := p.MarshalText(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
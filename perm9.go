@@ -0,0 +1,48 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+)
+
+// Perm9 is a compact form of a Perm holding only the 9 permission bits and the setuid, setgid, and
+// sticky bits, in the traditional unix encoding (so Perm9(0o4755) is setuid and 0755). At 2 bytes it
+// suits indices storing the modes of very many files, where file type bits are tracked separately or
+// not at all.
+type Perm9 uint16
+
+// Perm9 returns the permission and special bits of p as a Perm9, discarding file type and any other
+// bits, as fs.FileMode's Perm method does for the permission bits alone.
+func (p Perm) Perm9() Perm9 {
+	return Perm9(unixMode(p))
+}
+
+// Perm returns q as a Perm.
+func (q Perm9) Perm() Perm {
+	return fromUnix(uint64(q))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for this type. It accepts the same syntax as a
+// Perm, but returns an error if the value sets any bits that a Perm9 cannot hold. Note that numeric
+// values are read as a Perm, so `4755` is rejected; write setuid as `urwxr-xr-x` instead.
+func (q *Perm9) UnmarshalText(b []byte) error {
+	var p Perm
+	if err := p.UnmarshalText(b); err != nil {
+		return err
+	}
+	if fs.FileMode(p)&^chmodBits != 0 {
+		return fmt.Errorf("permission %s sets bits that Perm9 cannot hold", p.KeyString())
+	}
+	*q = p.Perm9()
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler for this type. It returns the String() representation.
+func (q Perm9) MarshalText() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// String returns the fs.FileMode string representation of q, exactly as for the equivalent Perm.
+func (q Perm9) String() string {
+	return q.Perm().String()
+}
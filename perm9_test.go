@@ -0,0 +1,53 @@
+package posixperm
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+	"unsafe"
+)
+
+func TestPerm9(t *testing.T) {
+	C := []struct {
+		p Perm
+		q Perm9
+	}{
+		{0o644, 0o644},
+		{Perm(fs.ModeSetuid | 0o755), 0o4755},
+		{Perm(fs.ModeSetgid | fs.ModeSticky | 0o770), 0o3770},
+		{Perm(fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky | 0o777), 0o7777},
+	}
+	for _, c := range C {
+		if q := c.p.Perm9(); q != c.q {
+			t.Errorf("with %v, expected %#o. got %#o", c.p, c.q, q)
+		}
+		if p := c.q.Perm(); p != c.p {
+			t.Errorf("with %#o, expected %v. got %v", c.q, c.p, p)
+		}
+		if s := c.q.String(); s != c.p.String() {
+			t.Errorf("with %#o, expected %q. got %q", c.q, c.p.String(), s)
+		}
+	}
+	if q := Perm(fs.ModeDir | 0o750).Perm9(); q != 0o750 {
+		t.Errorf("expected type bits to be discarded, got %#o", q)
+	}
+	if n := unsafe.Sizeof(Perm9(0)); n != 2 {
+		t.Errorf("expected Perm9 to be 2 bytes, got %d", n)
+	}
+}
+
+func TestPerm9Text(t *testing.T) {
+	var v struct{ Q Perm9 }
+	if err := json.Unmarshal([]byte(`{"Q":"urwxr-xr-x"}`), &v); err != nil || v.Q != 0o4755 {
+		t.Errorf("expected 04755, got %#o, %v", v.Q, err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) != `{"Q":"urwxr-xr-x"}` {
+		t.Errorf("expected round trip, got %s, %v", b, err)
+	}
+	for _, s := range []string{`{"Q":"drwxr-xr-x"}`, `{"Q":"4755"}`, `{"Q":"bogus"}`} {
+		if err := json.Unmarshal([]byte(s), &v); err == nil {
+			t.Errorf("got nil error for %s, unmarshaled to %#o", s, v.Q)
+		}
+	}
+}
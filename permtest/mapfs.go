@@ -0,0 +1,61 @@
+// Package permtest provides helpers for testing code that uses posixperm.
+package permtest
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing/fstest"
+
+	"github.com/ironiridis/posixperm"
+)
+
+// MapFS builds an fstest.MapFS from a compact declaration mapping each path to its mode and, for
+// regular files, optional contents. Modes may use any syntax accepted by posixperm.FromString, subject to opts.
+// A path ending in `/` is a directory, as is any mode carrying fs.ModeDir (eg `drwxr-x---`). It is
+// intended for tests of code that scans or checks modes:
+//
+//	fsys, err := permtest.MapFS(map[string][2]string{
+//		"etc/":       {"0755"},
+//		"etc/passwd": {"0644", "root:x:0:0::/root:/bin/sh\n"},
+//		"etc/shadow": {"u=rw"},
+//	})
+//
+// As with fstest.MapFS, parent directories that are not declared are synthesized with mode 0555.
+func MapFS(decl map[string][2]string, opts ...posixperm.Option) (fstest.MapFS, error) {
+	fsys := make(fstest.MapFS, len(decl))
+	for path, d := range decl {
+		p, err := posixperm.FromString(d[0], opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		m := fs.FileMode(p)
+		if strings.HasSuffix(path, "/") {
+			path = strings.TrimSuffix(path, "/")
+			if m.Type() != 0 && !m.IsDir() {
+				return nil, fmt.Errorf("%s: directory declared with mode %v", path, m)
+			}
+			m |= fs.ModeDir
+		}
+		if m.IsDir() && d[1] != "" {
+			return nil, fmt.Errorf("%s: directory declared with contents", path)
+		}
+		if !fs.ValidPath(path) {
+			return nil, fmt.Errorf("invalid path %q", path)
+		}
+		if _, ok := fsys[path]; ok {
+			return nil, fmt.Errorf("%s: declared more than once", path)
+		}
+		fsys[path] = &fstest.MapFile{Mode: m, Data: []byte(d[1])}
+	}
+	return fsys, nil
+}
+
+// MustMapFS is like MapFS but panics if decl is invalid. It simplifies tests with fixed declarations.
+func MustMapFS(decl map[string][2]string, opts ...posixperm.Option) fstest.MapFS {
+	fsys, err := MapFS(decl, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}
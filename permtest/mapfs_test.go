@@ -0,0 +1,60 @@
+package permtest
+
+import (
+	"io/fs"
+	"testing"
+
+	"github.com/ironiridis/posixperm"
+)
+
+func TestMapFS(t *testing.T) {
+	fsys, err := MapFS(map[string][2]string{
+		"etc/":       {"0755"},
+		"etc/passwd": {"0644", "root:x:0:0::/root:/bin/sh\n"},
+		"etc/shadow": {"u=rw"},
+		"srv":        {"drwxr-x---"},
+		"bin/su":     {"urwxr-xr-x"},
+	})
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	C := []struct {
+		path string
+		p    posixperm.Perm
+	}{
+		{"etc", posixperm.Perm(fs.ModeDir | 0o755)},
+		{"etc/passwd", 0o644},
+		{"etc/shadow", 0o600},
+		{"srv", posixperm.Perm(fs.ModeDir | 0o750)},
+		{"bin", posixperm.Perm(fs.ModeDir | 0o555)},
+		{"bin/su", posixperm.Perm(fs.ModeSetuid | 0o755)},
+	}
+	for _, c := range C {
+		fi, err := fs.Stat(fsys, c.path)
+		if err != nil || posixperm.Perm(fi.Mode()) != c.p {
+			t.Errorf("with %q, expected %v. got %v, %v", c.path, c.p, fi, err)
+		}
+	}
+	if b, err := fs.ReadFile(fsys, "etc/passwd"); err != nil || string(b) != "root:x:0:0::/root:/bin/sh\n" {
+		t.Errorf("expected passwd contents, got %q, %v", b, err)
+	}
+}
+
+func TestInvalidMapFS(t *testing.T) {
+	C := []map[string][2]string{
+		{"a": {"bogus"}},
+		{"a": {"7"}},
+		{"d/": {"0755", "contents"}},
+		{"d/": {"prwxr-xr-x"}},
+		{"/abs": {"0644"}},
+		{"d": {"0755"}, "d/": {"0755"}},
+	}
+	for _, c := range C {
+		if fsys, err := MapFS(c); err == nil {
+			t.Errorf("got nil error for %v, built %v", c, fsys)
+		}
+	}
+	if _, err := MapFS(map[string][2]string{"a": {"7"}}, posixperm.WithLenient()); err != nil {
+		t.Errorf("expected options to apply, got %v", err)
+	}
+}
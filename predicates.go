@@ -0,0 +1,81 @@
+package posixperm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+)
+
+// the write bits of every class
+const writeBits = 0o222
+
+// IsReadOnly reports whether p grants write permission to no class at all.
+func (p Perm) IsReadOnly() bool {
+	return p&writeBits == 0
+}
+
+// AsReadOnly returns p with the write permission of every class removed. All other bits, including
+// special and file type bits, are kept.
+func (p Perm) AsReadOnly() Perm {
+	return p &^ writeBits
+}
+
+// IsExecutableBy reports whether p grants execute (or for a directory, search) permission to class c.
+func (p Perm) IsExecutableBy(c Class) bool {
+	return p.Access(c).Has(AccessExecute)
+}
+
+// NeedsSticky reports whether p is a directory writable by other without the sticky bit, the /tmp
+// pattern gone wrong: anyone may then delete or rename anyone else's files in it. Unlike other world
+// writable paths, such a directory is usually meant to be shared, so the fix is to add the sticky bit
+// (`chmod +t`) rather than to remove the write permission. p must carry fs.ModeDir, as returned by
+// FromDirEntry or fs.FileInfo's Mode.
+func (p Perm) NeedsSticky() bool {
+	m := fs.FileMode(p)
+	return m.IsDir() && m&0o002 != 0 && m&fs.ModeSticky == 0
+}
+
+// WorldWritableNoSticky is a Matcher accepting the modes for which NeedsSticky reports true.
+var WorldWritableNoSticky Matcher = matcher{"world-writable-no-sticky", Perm.NeedsSticky}
+
+// ShouldBeExecutable reports whether contents, the start of a file, looks like something meant to be
+// executed: a script starting with a `#!` interpreter line, or an ELF executable or shared object.
+// Relocatable ELF objects (`.o` files) are not executable. Only the first 18 bytes are examined, so
+// callers need not read whole files. It is a heuristic for flagging files whose content and mode
+// disagree, such as a script committed without its execute bit.
+func ShouldBeExecutable(contents []byte) bool {
+	if bytes.HasPrefix(contents, []byte("#!")) {
+		return true
+	}
+	if len(contents) < 18 || !bytes.HasPrefix(contents, []byte("\x7fELF")) {
+		return false
+	}
+	// e_type follows the 16 byte identification, in the byte order given by EI_DATA
+	var order binary.ByteOrder = binary.LittleEndian
+	if contents[5] == 2 {
+		order = binary.BigEndian
+	}
+	switch order.Uint16(contents[16:]) {
+	case 2, 3: // ET_EXEC, ET_DYN
+		return true
+	}
+	return false
+}
+
+// the bits a private file may not set: any group or other permission, setuid, and setgid
+const publicBits = 0o077 | fs.ModeSetuid | fs.ModeSetgid
+
+// SecretFile is a Matcher accepting the modes suitable for key material and other secrets: those
+// granting nothing to group or other, and setting neither setuid nor setgid. See RequirePrivate.
+var SecretFile Matcher = matcher{"secret-file", func(p Perm) bool { return fs.FileMode(p)&publicBits == 0 }}
+
+// RequirePrivate returns an error naming the offending bits unless p satisfies SecretFile. It is a
+// one-call guard before loading key material, refusing files that others could read or modify, as
+// OpenSSH does for private keys.
+func RequirePrivate(p Perm) error {
+	if extra := fs.FileMode(p) & publicBits; extra != 0 {
+		return fmt.Errorf("permission %v is too open; bits %v must not be set", p, extra)
+	}
+	return nil
+}
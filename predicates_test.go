@@ -0,0 +1,121 @@
+package posixperm
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	C := []struct {
+		p  Perm
+		ro bool
+		v  Perm
+	}{
+		{0o444, true, 0o444},
+		{0o555, true, 0o555},
+		{0o644, false, 0o444},
+		{0o602, false, 0o400},
+		{0o020, false, 0o000},
+		{Perm(fs.ModeDir | fs.ModeSetgid | 0o775), false, Perm(fs.ModeDir | fs.ModeSetgid | 0o555)},
+	}
+	for _, c := range C {
+		if ro := c.p.IsReadOnly(); ro != c.ro {
+			t.Errorf("with %v, expected IsReadOnly %v. got %v", c.p, c.ro, ro)
+		}
+		if v := c.p.AsReadOnly(); v != c.v || !v.IsReadOnly() {
+			t.Errorf("with %v, expected %v. got %v", c.p, c.v, v)
+		}
+	}
+}
+
+func TestIsExecutableBy(t *testing.T) {
+	p := Perm(0o751)
+	if !p.IsExecutableBy(ClassOwner) || !p.IsExecutableBy(ClassGroup) || !p.IsExecutableBy(ClassOther) {
+		t.Errorf("expected %v to be executable by every class", p)
+	}
+	p = Perm(fs.ModeSetuid | 0o640)
+	if p.IsExecutableBy(ClassOwner) || p.IsExecutableBy(ClassGroup) || p.IsExecutableBy(ClassOther) {
+		t.Errorf("expected %v to be executable by no class", p)
+	}
+}
+
+func TestNeedsSticky(t *testing.T) {
+	dir := Perm(fs.ModeDir)
+	C := []struct {
+		p    Perm
+		want bool
+	}{
+		{dir | 0o777, true},
+		{dir | 0o773, true},
+		{dir | Perm(fs.ModeSticky) | 0o777, false},
+		{dir | 0o775, false},
+		{0o777, false},
+		{Perm(fs.ModeSymlink) | 0o777, false},
+	}
+	for _, c := range C {
+		if got := c.p.NeedsSticky(); got != c.want {
+			t.Errorf("with %v, expected %v. got %v", c.p, c.want, got)
+		}
+		if got := WorldWritableNoSticky.Match(c.p); got != c.want {
+			t.Errorf("with %v, expected matcher to agree. got %v", c.p, got)
+		}
+	}
+}
+
+func TestShouldBeExecutable(t *testing.T) {
+	elf := func(data, typ byte) []byte {
+		b := append([]byte("\x7fELF"), 2, data, 1)
+		b = append(b, make([]byte, 9)...)
+		if data == 2 {
+			return append(b, 0, typ)
+		}
+		return append(b, typ, 0)
+	}
+	C := []struct {
+		b []byte
+		v bool
+	}{
+		{[]byte("#!/bin/sh\necho hi\n"), true},
+		{[]byte("#!"), true},
+		{[]byte("# comment\n#!/bin/sh\n"), false},
+		{[]byte("hello"), false},
+		{nil, false},
+		{elf(1, 2), true},
+		{elf(1, 3), true},
+		{elf(2, 2), true},
+		{elf(1, 1), false},
+		{elf(1, 4), false},
+		{[]byte("\x7fELF"), false},
+	}
+	for _, c := range C {
+		if v := ShouldBeExecutable(c.b); v != c.v {
+			t.Errorf("with %q, expected %v. got %v", c.b, c.v, v)
+		}
+	}
+}
+
+func TestRequirePrivate(t *testing.T) {
+	C := []struct {
+		p  Perm
+		ok bool
+	}{
+		{0o600, true},
+		{0o400, true},
+		{0o700, true},
+		{0o000, true},
+		{Perm(fs.ModeDir | 0o700), true},
+		{0o640, false},
+		{0o604, false},
+		{0o610, false},
+		{Perm(fs.ModeSetuid | 0o700), false},
+		{Perm(fs.ModeSetgid | 0o600), false},
+	}
+	for _, c := range C {
+		if err := RequirePrivate(c.p); (err == nil) != c.ok {
+			t.Errorf("with %v, expected ok %v. got %v", c.p, c.ok, err)
+		}
+		if m := SecretFile.Match(c.p); m != c.ok {
+			t.Errorf("with %v, expected SecretFile match %v. got %v", c.p, c.ok, m)
+		}
+	}
+}
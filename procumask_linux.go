@@ -0,0 +1,44 @@
+//go:build linux
+
+package posixperm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ProcessUmask returns the umask of the process pid, as reported by the Umask line of
+// /proc/<pid>/status, which is useful for diagnosing why a daemon creates files with unexpected
+// modes. It is only available on Linux 4.7 and later, and reading the status of another user's
+// process may be refused.
+func ProcessUmask(pid int) (Umask, error) {
+	f, err := os.Open("/proc/" + strconv.Itoa(pid) + "/status")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	u, err := procStatusUmask(f)
+	if err != nil {
+		return 0, fmt.Errorf("cannot read umask of process %d: %w", pid, err)
+	}
+	return u, nil
+}
+
+// procStatusUmask returns the umask from the contents of a /proc/<pid>/status file.
+func procStatusUmask(r io.Reader) (Umask, error) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		if v, ok := strings.CutPrefix(sc.Text(), "Umask:"); ok {
+			return ParseUmask(strings.TrimSpace(v))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return 0, err
+	}
+	return 0, errors.New("no Umask line in process status; Linux 4.7 or later is required")
+}
@@ -0,0 +1,47 @@
+//go:build linux
+
+package posixperm
+
+import (
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestProcStatusUmask(t *testing.T) {
+	C := []struct {
+		status string
+		want   Umask
+		ok     bool
+	}{
+		{"Name:\tsshd\nUmask:\t0077\nState:\tS (sleeping)\n", 0o077, true},
+		{"Name:\tnginx\nUmask:\t0022\n", 0o022, true},
+		{"Name:\told\nState:\tS (sleeping)\n", 0, false},
+		{"Umask:\tbogus\n", 0, false},
+	}
+	for _, c := range C {
+		u, err := procStatusUmask(strings.NewReader(c.status))
+		if c.ok && (err != nil || u != c.want) {
+			t.Errorf("with %q, expected %v. got %v, %v", c.status, c.want, u, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %q, expected error, got %v", c.status, u)
+		}
+	}
+}
+
+func TestProcessUmask(t *testing.T) {
+	old := syscall.Umask(0o027)
+	defer syscall.Umask(old)
+	u, err := ProcessUmask(os.Getpid())
+	if err != nil {
+		t.Skipf("cannot read own umask: %v", err)
+	}
+	if u != 0o027 {
+		t.Errorf("expected 0027, got %v", u)
+	}
+	if _, err := ProcessUmask(-1); err == nil {
+		t.Errorf("expected error for an invalid pid")
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ProcessUmask returns the umask of the process pid, as reported by the Umask line of
+// /proc/<pid>/status. It is only available on Linux.
+func ProcessUmask(pid int) (Umask, error) {
+	return 0, fmt.Errorf("cannot read umask of process %d: %w", pid, errors.ErrUnsupported)
+}
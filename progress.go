@@ -0,0 +1,81 @@
+package posixperm
+
+// Progress reports how far a tree walk has got. See WithProgress and ScanProgress.
+type Progress struct {
+	// Entries counts the entries visited so far.
+	Entries int
+	// Findings counts what the walk reports: the paths changed (or with DryRun, to be changed) by
+	// ApplyExpr, or the findings of an audit. It is always 0 for Stats.
+	Findings int
+	// Path is the entry most recently visited.
+	Path string
+}
+
+// ProgressFunc receives progress reports. It is called synchronously from the walk, so it should
+// return quickly.
+type ProgressFunc func(Progress)
+
+// WithProgress makes ApplyExpr call fn after every `every` entries it visits (or after each, if every
+// is less than 1), and once more when the walk ends, so that callers can display progress on large
+// trees.
+func WithProgress(every int, fn ProgressFunc) ApplyOption {
+	return func(o *applyOptions) { o.progress = newProgressTracker(every, fn) }
+}
+
+// ScanOption configures the read-only tree scanners Stats, AuditHomes, and AuditSetgidTree.
+type ScanOption func(*scanOptions)
+
+type scanOptions struct {
+	progress *progressTracker
+	errs     treeErrors
+	xdev     *xdev
+}
+
+// ScanProgress makes a scanner call fn after every `every` entries it visits (or after each, if every
+// is less than 1), and once more when the scan ends.
+func ScanProgress(every int, fn ProgressFunc) ScanOption {
+	return func(o *scanOptions) { o.progress = newProgressTracker(every, fn) }
+}
+
+func newScanOptions(opts []ScanOption) scanOptions {
+	o := scanOptions{errs: treeErrors{policy: FailFast}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// progressTracker counts the entries of a walk and reports them at a fixed cadence. A nil
+// *progressTracker does nothing, so walks need not check for one.
+type progressTracker struct {
+	every int
+	fn    ProgressFunc
+	Progress
+}
+
+func newProgressTracker(every int, fn ProgressFunc) *progressTracker {
+	if every < 1 {
+		every = 1
+	}
+	return &progressTracker{every: every, fn: fn}
+}
+
+// visit records an entry, and any findings it produced, and reports progress if it is due.
+func (t *progressTracker) visit(path string, findings int) {
+	if t == nil {
+		return
+	}
+	t.Entries++
+	t.Findings += findings
+	t.Path = path
+	if t.Entries%t.every == 0 {
+		t.fn(t.Progress)
+	}
+}
+
+// done reports the final progress, unless it was just reported.
+func (t *progressTracker) done() {
+	if t != nil && t.Entries%t.every != 0 {
+		t.fn(t.Progress)
+	}
+}
@@ -0,0 +1,49 @@
+package posixperm
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestProgress(t *testing.T) {
+	var got []Progress
+	record := func(p Progress) { got = append(got, p) }
+
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o600, "b": 0o644, "c": 0o600, "d": 0o644})
+	if _, err := ApplyExpr(root, "a+r", WithProgress(2, record)); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	// the root, a, and c change; the final report follows the fifth entry
+	want := []Progress{{2, 2, filepath.Join(root, "a")}, {4, 3, filepath.Join(root, "c")}, {5, 3, filepath.Join(root, "d")}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("at %d, expected %v. got %v", i, want[i], got[i])
+		}
+	}
+
+	got = nil
+	fsys := fstest.MapFS{
+		"alice":        {Mode: fs.ModeDir | 0o700},
+		"bob":          {Mode: fs.ModeDir | 0o755},
+		"bob/.profile": {Mode: 0o666},
+	}
+	if _, err := AuditHomes(fsys, ScanProgress(0, record)); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if len(got) != 3 || got[2] != (Progress{3, 2, "bob/.profile"}) {
+		t.Errorf("expected a report per entry, got %v", got)
+	}
+
+	got = nil
+	if _, err := Stats(fstest.MapFS{"a": {}, "b": {}}, ScanProgress(3, record)); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if len(got) != 1 || got[0].Entries != 3 {
+		t.Errorf("expected a single report of 3 entries, got %v", got)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build race
+
+package posixperm
+
+// raceEnabled is set when testing with the race detector, which adds allocations of its own.
+const raceEnabled = true
@@ -0,0 +1,45 @@
+package posixperm
+
+import "log/slog"
+
+// RedactedPlaceholder is rendered in place of a Perm hidden by Redact.
+const RedactedPlaceholder = "[redacted]"
+
+// Redacted is a Perm that may be hidden when rendered. See Redact.
+type Redacted struct {
+	p    Perm
+	hide bool
+}
+
+// Redact returns p wrapped so that it renders as RedactedPlaceholder if sensitive matches it, and as
+// p.String() otherwise, for compliance regimes that treat the permission detail of key material as
+// sensitive. Redacted implements fmt.Stringer, encoding.TextMarshaler, and slog.LogValuer, so it can
+// be passed wherever a Perm would be logged or marshaled. A nil sensitive never redacts.
+func Redact(p Perm, sensitive Matcher) Redacted {
+	return Redacted{p: p, hide: sensitive != nil && sensitive.Match(p)}
+}
+
+// String returns p.String(), or RedactedPlaceholder if p is hidden.
+func (r Redacted) String() string {
+	if r.hide {
+		return RedactedPlaceholder
+	}
+	return r.p.String()
+}
+
+// MarshalText implements encoding.TextMarshaler for this type. It returns the String() representation.
+func (r Redacted) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// LogValue implements slog.LogValuer for this type, logging the String() representation.
+func (r Redacted) LogValue() slog.Value {
+	return slog.StringValue(r.String())
+}
+
+// WithRedaction makes the records logged by WithLogger hide the before and after modes matching
+// sensitive, eg SecretFile. See Redact. Journal entries are not redacted, as they are needed to roll
+// changes back.
+func WithRedaction(sensitive Matcher) ApplyOption {
+	return func(o *applyOptions) { o.sensitive = sensitive }
+}
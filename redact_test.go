@@ -0,0 +1,57 @@
+package posixperm
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/fs"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	C := []struct {
+		p         Perm
+		sensitive Matcher
+		want      string
+	}{
+		{0o600, SecretFile, RedactedPlaceholder},
+		{0o400, SecretFile, RedactedPlaceholder},
+		{0o644, SecretFile, "-rw-r--r--"},
+		{0o600, nil, "-rw-------"},
+		{0o777, AtLeast(0o002), RedactedPlaceholder},
+	}
+	for _, c := range C {
+		r := Redact(c.p, c.sensitive)
+		if got := r.String(); got != c.want {
+			t.Errorf("with %v, expected %q. got %q", c.p, c.want, got)
+		}
+		b, err := json.Marshal(map[string]Redacted{"mode": r})
+		if err != nil || string(b) != `{"mode":"`+c.want+`"}` {
+			t.Errorf("with %v, expected JSON to carry %q. got %s, %v", c.p, c.want, b, err)
+		}
+		var buf bytes.Buffer
+		slog.New(slog.NewTextHandler(&buf, nil)).Info("x", "mode", r)
+		if !strings.Contains(buf.String(), "mode="+c.want) {
+			t.Errorf("with %v, expected log record to carry %q. got %q", c.p, c.want, buf.String())
+		}
+	}
+}
+
+func TestApplyExprRedaction(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o600})
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	if _, err := ApplyExpr(root, "a+r", WithLogger(logger), WithRedaction(SecretFile)); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	var r map[string]any
+	for _, line := range strings.SplitAfter(strings.TrimSpace(buf.String()), "\n") {
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatal(err)
+		}
+		if r["before"] != RedactedPlaceholder || r["after"] == RedactedPlaceholder {
+			t.Errorf("expected only the private before mode to be redacted, got %v", r)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package posixperm
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// ReadJournal decodes the newline delimited JSON entries written by WithJournal.
+func ReadJournal(r io.Reader) ([]JournalEntry, error) {
+	var entries []JournalEntry
+	dec := json.NewDecoder(r)
+	for {
+		var e JournalEntry
+		err := dec.Decode(&e)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return entries, fmt.Errorf("cannot read journal entry %d: %w", len(entries)+1, err)
+		}
+		entries = append(entries, e)
+	}
+}
+
+// Rollback returns a plan undoing the changes recorded in journal: an entry restoring the Before mode
+// of each change, in reverse order, so that a path changed more than once ends up with its original
+// mode and directories are made accessible again before their contents are restored. Entries that
+// were only planned by a dry run are skipped. Each returned entry has the Rule "rollback" and a zero
+// Time.
+func Rollback(journal []JournalEntry) []JournalEntry {
+	var plan []JournalEntry
+	for i := len(journal) - 1; i >= 0; i-- {
+		e := journal[i]
+		if e.DryRun {
+			continue
+		}
+		plan = append(plan, JournalEntry{Path: e.Path, Before: e.After, After: e.Before, Rule: "rollback"})
+	}
+	return plan
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// unixMode returns the traditional 12 bit unix encoding of the permission and special bits of p.
+func unixMode(p Perm) uint32 {
+	return uint32(fs.FileMode(p).Perm()) | unixSpecial(p)
+}
+
+// WriteRollbackScript writes a POSIX shell script to w that undoes the changes recorded in journal
+// using chmod(1), following the plan returned by Rollback. The script stops at the first failure.
+func WriteRollbackScript(w io.Writer, journal []JournalEntry) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "#!/bin/sh")
+	fmt.Fprintln(bw, "# restores the modes recorded in a posixperm change journal")
+	fmt.Fprintln(bw, "set -e")
+	for _, e := range Rollback(journal) {
+		// 5 digits, so that GNU chmod also clears setuid and setgid on directories
+		fmt.Fprintf(bw, "chmod -- %05o %s\n", unixMode(e.After), shellQuote(e.Path))
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,83 @@
+package posixperm
+
+import (
+	"bytes"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRollback(t *testing.T) {
+	journal := []JournalEntry{
+		{Path: "a", Before: 0o600, After: 0o644, Rule: "go+r"},
+		{Path: "b", Before: 0o600, After: 0o700, Rule: "u+x", DryRun: true},
+		{Path: "a", Before: 0o644, After: 0o664, Rule: "g+w"},
+	}
+	want := []JournalEntry{
+		{Path: "a", Before: 0o664, After: 0o644, Rule: "rollback"},
+		{Path: "a", Before: 0o644, After: 0o600, Rule: "rollback"},
+	}
+	if plan := Rollback(journal); !reflect.DeepEqual(plan, want) {
+		t.Errorf("expected %+v, got %+v", want, plan)
+	}
+}
+
+func TestReadJournal(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o600, "b": 0o640})
+	var buf bytes.Buffer
+	if _, err := ApplyExpr(root, "go=r", WithJournal(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadJournal(&buf)
+	if err != nil {
+		t.Fatalf("got error reading journal: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Errorf("expected 3 entries, got %+v", entries)
+	}
+	if _, err := ReadJournal(strings.NewReader("{}\nnot json\n")); err == nil {
+		t.Errorf("expected error reading corrupt journal")
+	}
+}
+
+func TestWriteRollbackScript(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{
+		"it's":  0o600,
+		"-flag": fs.ModeSetuid | 0o700,
+	})
+	var journal bytes.Buffer
+	if _, err := ApplyExpr(root, "a=rwx", WithJournal(&journal)); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadJournal(&journal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var script bytes.Buffer
+	if err := WriteRollbackScript(&script, entries); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(script.String(), `'\''`) {
+		t.Errorf("expected quoted single quote in script:\n%s", script.String())
+	}
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no shell available to run the rollback script")
+	}
+	if out, err := exec.Command(sh, "-c", script.String()).CombinedOutput(); err != nil {
+		t.Fatalf("rollback script failed: %v\n%s\n%s", err, out, script.String())
+	}
+	C := map[string]fs.FileMode{
+		"":      0o700,
+		"it's":  0o600,
+		"-flag": fs.ModeSetuid | 0o700,
+	}
+	for name, want := range C {
+		if got := modeOf(t, filepath.Join(root, name)); got != want {
+			t.Errorf("with %q, expected %v after rollback. got %v", name, want, got)
+		}
+	}
+}
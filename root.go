@@ -0,0 +1,30 @@
+package posixperm
+
+import (
+	"io/fs"
+	"os"
+)
+
+// mutator is the subset of *os.Root used to change files, so that the same code can operate on
+// host paths or be confined to a root.
+type mutator interface {
+	Lstat(name string) (fs.FileInfo, error)
+	Chown(name string, uid, gid int) error
+	Chmod(name string, mode fs.FileMode) error
+}
+
+// hostPaths is a mutator for unconfined host paths.
+type hostPaths struct{}
+
+func (hostPaths) Lstat(name string) (fs.FileInfo, error)    { return os.Lstat(name) }
+func (hostPaths) Chown(name string, uid, gid int) error     { return os.Chown(name, uid, gid) }
+func (hostPaths) Chmod(name string, mode fs.FileMode) error { return os.Chmod(name, mode) }
+
+// WithinRoot makes ApplyExpr change modes through an os.Root opened on the root of the walk, so that
+// no change can land outside the tree, even if a directory in it is replaced by a symbolic link to
+// somewhere else while the walk is in progress. Agents running as root over trees that others can
+// write to should use it. It requires Go 1.25 or later; built with an earlier release, ApplyExpr
+// given WithinRoot returns an error wrapping errors.ErrUnsupported before changing anything.
+func WithinRoot() ApplyOption {
+	return func(o *applyOptions) { o.confine = true }
+}
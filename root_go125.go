@@ -0,0 +1,38 @@
+//go:build go1.25
+
+package posixperm
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// confinedChmod opens an os.Root on dir and returns a function changing modes of paths below dir
+// through it, along with the root to close once the walk is done.
+func confinedChmod(dir string) (func(path string, mode fs.FileMode) error, io.Closer, error) {
+	r, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func(path string, mode fs.FileMode) error {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		return r.Chmod(rel, mode)
+	}, r, nil
+}
+
+// ApplyIn is like Apply, but operates on the file name within r, refusing to follow any symbolic
+// link or ".." out of it. It requires Go 1.25 or later.
+func (f FileSpec) ApplyIn(r *os.Root, name string) error {
+	return f.apply(r, name)
+}
+
+// ApplyIn is like Apply, but operates on the file name within r, refusing to follow any symbolic
+// link or ".." out of it. It requires Go 1.25 or later.
+func (o Ownership) ApplyIn(r *os.Root, name string) error {
+	return o.apply(r, name)
+}
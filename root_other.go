@@ -0,0 +1,15 @@
+//go:build !go1.25
+
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// confinedChmod reports that WithinRoot is unsupported, as os.Root cannot change modes before Go 1.25.
+func confinedChmod(dir string) (func(path string, mode fs.FileMode) error, io.Closer, error) {
+	return nil, nil, fmt.Errorf("WithinRoot requires Go 1.25 or later: %w", errors.ErrUnsupported)
+}
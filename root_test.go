@@ -0,0 +1,64 @@
+//go:build go1.25
+
+package posixperm
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyExprWithinRoot(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{
+		"a/":  0o700,
+		"a/f": 0o600,
+	})
+	if _, err := ApplyExpr(root, "go+rX", WithinRoot()); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	C := map[string]fs.FileMode{"": 0o755, "a": 0o755, "a/f": 0o644}
+	for name, want := range C {
+		if got := modeOf(t, filepath.Join(root, name)); got != want {
+			t.Errorf("with %q, expected %v. got %v", name, want, got)
+		}
+	}
+}
+
+func TestApplyInEscape(t *testing.T) {
+	outside := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(outside, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "f"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	r, err := os.OpenRoot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	f := FileSpec{Mode: 0o644}
+	for _, name := range []string{"link", "../secret", "/etc/passwd"} {
+		if err := f.ApplyIn(r, name); err == nil {
+			t.Errorf("with %q, expected error applying outside of root", name)
+		}
+	}
+	if got := modeOf(t, outside); got != 0o600 {
+		t.Errorf("expected file outside of root to keep mode 0600, got %v", got)
+	}
+	if err := f.ApplyIn(r, "f"); err != nil {
+		t.Errorf("got error applying within root: %v", err)
+	}
+	if got := modeOf(t, filepath.Join(dir, "f")); got != 0o644 {
+		t.Errorf("expected 0644 within root, got %v", got)
+	}
+	if err := (Ownership{User: "0"}).ApplyIn(r, "link"); err == nil {
+		t.Errorf("expected error changing ownership outside of root")
+	}
+}
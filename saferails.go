@@ -0,0 +1,87 @@
+package posixperm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrPreserveRoot is returned by ApplyExpr when asked to walk the root directory of the system,
+// unless NoPreserveRoot is given.
+var ErrPreserveRoot = errors.New("refusing to apply a mode recursively to /")
+
+// NoPreserveRoot allows ApplyExpr to walk the root directory of the system, which like
+// `chmod --preserve-root` it otherwise refuses to do, as a bug that gets there is catastrophic.
+func NoPreserveRoot() ApplyOption {
+	return func(o *applyOptions) { o.noPreserveRoot = true }
+}
+
+// TreeLimitError is returned by ApplyExpr, before any mode is changed, for a tree exceeding the limits
+// set by WithLimits.
+type TreeLimitError struct {
+	Root string
+	// Limit is "entries" or "depth".
+	Limit string
+	Max   int
+}
+
+func (e *TreeLimitError) Error() string {
+	return fmt.Sprintf("%s exceeds the limit of %d %s; raise the limit to proceed", e.Root, e.Max, e.Limit)
+}
+
+// WithLimits makes ApplyExpr count the entries in the tree, and measure its depth below root, before
+// changing anything, and refuse with a *TreeLimitError if there are more than maxEntries or they are
+// nested deeper than maxDepth, so that a mistaken root cannot change a whole filesystem. A limit of 0
+// or less is no limit. The count is an extra walk of the tree, not following symbolic links, that
+// leaves out the same paths as ApplyExpr does for SkipPaths, OneFileSystem, and SkipSyntheticModes.
+func WithLimits(maxEntries, maxDepth int) ApplyOption {
+	return func(o *applyOptions) { o.maxEntries, o.maxDepth = maxEntries, maxDepth }
+}
+
+// checkRails enforces NoPreserveRoot and WithLimits for a walk of root.
+func (o *applyOptions) checkRails(ctx context.Context, root string) error {
+	if !o.noPreserveRoot {
+		fi, err := os.Stat(root)
+		if err == nil {
+			if sys, serr := os.Stat("/"); serr == nil && os.SameFile(fi, sys) {
+				return ErrPreserveRoot
+			}
+		}
+	}
+	if o.maxEntries <= 0 && o.maxDepth <= 0 {
+		return nil
+	}
+	var x *xdev
+	if o.xdev != nil {
+		x = new(xdev) // the walk proper tracks its own root device
+	}
+	var entries int
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return nil // reported by the walk proper
+		}
+		if d.Type()&fs.ModeSymlink == 0 && o.pruned(root, path, d, x) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		entries++
+		if o.maxEntries > 0 && entries > o.maxEntries {
+			return &TreeLimitError{Root: root, Limit: "entries", Max: o.maxEntries}
+		}
+		if rel, rerr := filepath.Rel(root, path); o.maxDepth > 0 && rerr == nil && rel != "." &&
+			strings.Count(rel, string(filepath.Separator)) >= o.maxDepth {
+			return &TreeLimitError{Root: root, Limit: "depth", Max: o.maxDepth}
+		}
+		return nil
+	})
+	return err
+}
@@ -0,0 +1,60 @@
+package posixperm
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+func TestPreserveRoot(t *testing.T) {
+	for _, root := range []string{"/", "/.", "//", "/tmp/.."} {
+		if _, err := ApplyExpr(root, "u+r", DryRun()); !errors.Is(err, ErrPreserveRoot) {
+			t.Errorf("with %q, expected ErrPreserveRoot, got %v", root, err)
+		}
+	}
+}
+
+func TestWithLimits(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{
+		"a":     0o600,
+		"b/":    0o700,
+		"b/c":   0o600,
+		"b/d/":  0o700,
+		"b/d/e": 0o600,
+	})
+	C := []struct {
+		entries, depth int
+		limit          string
+	}{
+		{0, 0, ""},
+		{6, 3, ""},
+		{5, 0, "entries"},
+		{0, 2, "depth"},
+		{100, 1, "depth"},
+	}
+	for _, c := range C {
+		results, err := ApplyExpr(root, "go+r", DryRun(), WithLimits(c.entries, c.depth))
+		var le *TreeLimitError
+		switch {
+		case c.limit == "" && err != nil:
+			t.Errorf("with limits %d and %d, got error: %v", c.entries, c.depth, err)
+		case c.limit != "" && (!errors.As(err, &le) || le.Limit != c.limit || results != nil):
+			t.Errorf("with limits %d and %d, expected %s limit error and no results, got %v", c.entries, c.depth, c.limit, err)
+		}
+	}
+	if got := modeOf(t, filepath.Join(root, "a")); got != 0o600 {
+		t.Errorf("expected no change, got %v", got)
+	}
+
+	skip := SkipPaths(func(path string, d fs.DirEntry) bool { return filepath.Base(path) == "d" })
+	if _, err := ApplyExpr(root, "go+r", DryRun(), WithLimits(4, 2), skip); err != nil {
+		t.Errorf("expected skipped paths not to count toward limits, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ApplyExprContext(ctx, root, "go+r", DryRun(), WithLimits(100, 100)); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the count to stop once ctx is done, got %v", err)
+	}
+}
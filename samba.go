@@ -0,0 +1,82 @@
+package posixperm
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SambaMasks holds the smb.conf(5) parameters controlling the mode of files and directories created
+// through an SMB share. The zero value is not the Samba default; see DefaultSambaMasks.
+type SambaMasks struct {
+	CreateMask         Perm // `create mask`, or its synonym `create mode`
+	ForceCreateMode    Perm // `force create mode`
+	DirectoryMask      Perm // `directory mask`, or its synonym `directory mode`
+	ForceDirectoryMode Perm // `force directory mode`
+}
+
+// DefaultSambaMasks returns the masks Samba uses for a share that does not set them.
+func DefaultSambaMasks() SambaMasks {
+	return SambaMasks{CreateMask: 0o744, DirectoryMask: 0o755}
+}
+
+// ParseSambaMasks reads the parameters of a share section of smb.conf, one `name = value` per line,
+// starting from DefaultSambaMasks. Parameter names are matched as Samba does, ignoring case, spaces,
+// and underscores. Other parameters, comments starting with `#` or `;`, and section headers are
+// ignored, so the text of a whole share section may be passed.
+func ParseSambaMasks(section string) (SambaMasks, error) {
+	m := DefaultSambaMasks()
+	sc := bufio.NewScanner(strings.NewReader(section))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' || line[0] == '[' {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		if _, err := m.Set(name, value); err != nil {
+			return m, err
+		}
+	}
+	return m, sc.Err()
+}
+
+// Set sets the parameter name to value, an octal mode such as `0664`, reporting whether name is one of
+// the parameters held by SambaMasks.
+func (m *SambaMasks) Set(name, value string) (bool, error) {
+	var p *Perm
+	switch strings.NewReplacer(" ", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(name))) {
+	case "createmask", "createmode":
+		p = &m.CreateMask
+	case "forcecreatemode":
+		p = &m.ForceCreateMode
+	case "directorymask", "directorymode":
+		p = &m.DirectoryMask
+	case "forcedirectorymode":
+		p = &m.ForceDirectoryMode
+	default:
+		return false, nil
+	}
+	value = strings.TrimSpace(value)
+	v, err := strconv.ParseUint(value, 8, 32)
+	if err != nil || v&^0o7777 != 0 {
+		return true, fmt.Errorf("samba parameter %q has invalid mode %q", strings.TrimSpace(name), value)
+	}
+	*p = fromUnix(v)
+	return true, nil
+}
+
+// Created predicts the mode of a file (or directory, if isDir is set) created through the share, where
+// requested is the mode Samba derives from the client's request: typically 0666 for a file, or 0766
+// if the DOS archive attribute is mapped to the owner execute bit as it is by default, and 0777 for a
+// directory. The mask is applied first and the forced bits are then added. The `inherit permissions`
+// parameter, which replaces this calculation, and POSIX ACLs are not modeled.
+func (m SambaMasks) Created(requested Perm, isDir bool) Perm {
+	if isDir {
+		return requested&m.DirectoryMask&Perm(chmodBits) | m.ForceDirectoryMode
+	}
+	return requested&m.CreateMask&Perm(chmodBits) | m.ForceCreateMode
+}
@@ -0,0 +1,60 @@
+package posixperm
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestParseSambaMasks(t *testing.T) {
+	m, err := ParseSambaMasks(`
+[projects]
+	path = /srv/projects
+	; masks for a collaborative share
+	create mask = 0664
+	Force_Create_Mode = 0660
+	directory mode = 2775
+	force directory mode = 02770
+`)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	want := SambaMasks{
+		CreateMask:         0o664,
+		ForceCreateMode:    0o660,
+		DirectoryMask:      Perm(fs.ModeSetgid) | 0o775,
+		ForceDirectoryMode: Perm(fs.ModeSetgid) | 0o770,
+	}
+	if m != want {
+		t.Errorf("expected %+v, got %+v", want, m)
+	}
+	if m, err := ParseSambaMasks("[homes]\nbrowseable = no"); err != nil || m != DefaultSambaMasks() {
+		t.Errorf("expected defaults, got %+v, %v", m, err)
+	}
+	for _, c := range []string{"create mask = 0999", "directory mask = rwx", "force create mode = 017777"} {
+		if _, err := ParseSambaMasks(c); err == nil {
+			t.Errorf("with %q, expected error", c)
+		}
+	}
+}
+
+func TestSambaCreated(t *testing.T) {
+	shared := SambaMasks{CreateMask: 0o664, ForceCreateMode: 0o660, DirectoryMask: 0o775, ForceDirectoryMode: Perm(fs.ModeSetgid) | 0o770}
+	C := []struct {
+		m         SambaMasks
+		requested Perm
+		isDir     bool
+		want      Perm
+	}{
+		{DefaultSambaMasks(), 0o766, false, 0o744},
+		{DefaultSambaMasks(), 0o666, false, 0o644},
+		{DefaultSambaMasks(), 0o777, true, 0o755},
+		{shared, 0o766, false, 0o664},
+		{shared, 0o444, false, 0o664},
+		{shared, 0o777, true, Perm(fs.ModeSetgid) | 0o775},
+	}
+	for _, c := range C {
+		if got := c.m.Created(c.requested, c.isDir); got != c.want {
+			t.Errorf("with %v (dir %v) under %+v, expected %v. got %v", c.requested, c.isDir, c.m, c.want, got)
+		}
+	}
+}
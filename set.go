@@ -0,0 +1,98 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+// Set is a collection of acceptable modes, for policies that enumerate allowed values rather than
+// bound them. The zero Set is empty. Sets are immutable once built, so they may be shared freely.
+type Set struct {
+	perms []Perm // sorted and unique
+}
+
+// NewSet returns a Set holding each of perms. Duplicates are ignored.
+func NewSet(perms ...Perm) Set {
+	s := append([]Perm(nil), perms...)
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	out := s[:0]
+	for i, p := range s {
+		if i == 0 || p != s[i-1] {
+			out = append(out, p)
+		}
+	}
+	return Set{perms: out}
+}
+
+// ParseSet parses s as a `|` separated list of modes in braces, eg `{0644|0640|0600}`. Each mode may
+// use any syntax accepted by FromString; the braces may be omitted.
+func ParseSet(s string) (r Set, err error) {
+	err = r.UnmarshalText([]byte(s))
+	return
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for this type, following the rules of ParseSet.
+func (s *Set) UnmarshalText(b []byte) error {
+	t := strings.TrimSpace(string(b))
+	if strings.HasPrefix(t, "{") != strings.HasSuffix(t, "}") {
+		return fmt.Errorf("unbalanced braces in permission set %q", b)
+	}
+	t = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(t, "{"), "}"))
+	if t == "" {
+		*s = Set{}
+		return nil
+	}
+	var perms []Perm
+	for _, item := range strings.Split(t, "|") {
+		var p Perm
+		if err := p.UnmarshalText([]byte(strings.TrimSpace(item))); err != nil {
+			return fmt.Errorf("cannot parse permission set %q: %w", b, err)
+		}
+		perms = append(perms, p)
+	}
+	*s = NewSet(perms...)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler for this type. It returns the String() representation.
+func (s Set) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// String returns the modes of s in ascending order, in explicit octal form, eg `{0600|0640|0644}`.
+// Modes with special or file type bits, which ParseSet would not read back from octal, are instead
+// given in fs.FileMode's form, eg `{0640|drwxr-x---}`.
+func (s Set) String() string {
+	items := make([]string, len(s.perms))
+	for i, p := range s.perms {
+		if fs.FileMode(p)&^fs.ModePerm != 0 {
+			items[i] = p.String()
+		} else {
+			items[i] = octal(p)
+		}
+	}
+	return "{" + strings.Join(items, "|") + "}"
+}
+
+// Contains reports whether p is one of the modes in s.
+func (s Set) Contains(p Perm) bool {
+	i := sort.Search(len(s.perms), func(i int) bool { return s.perms[i] >= p })
+	return i < len(s.perms) && s.perms[i] == p
+}
+
+// Union returns a Set holding the modes of both s and o.
+func (s Set) Union(o Set) Set {
+	return NewSet(append(append([]Perm(nil), s.perms...), o.perms...)...)
+}
+
+// Len returns the number of modes in s.
+func (s Set) Len() int {
+	return len(s.perms)
+}
+
+// Perms returns the modes of s in ascending order.
+func (s Set) Perms() []Perm {
+	return append([]Perm(nil), s.perms...)
+}
@@ -0,0 +1,62 @@
+package posixperm
+
+import (
+	"encoding/json"
+	"io/fs"
+	"testing"
+)
+
+func TestParseSet(t *testing.T) {
+	C := []struct {
+		s  string
+		v  string
+		ok bool
+	}{
+		{"{0644|0640|0600}", "{0600|0640|0644}", true},
+		{"0644 | rw-r----- | 0640", "{0640|0644}", true},
+		{"{u=rw g=r|0600}", "{0600|0640}", true},
+		{"{drwxr-x---}", "{drwxr-x---}", true},
+		{"{0o40000755|0755}", "{0755|urwxr-xr-x}", true},
+		{"{}", "{}", true},
+		{"{0644", "", false},
+		{"{0644||0600}", "", false},
+		{"{0644|bogus}", "", false},
+	}
+	for _, c := range C {
+		s, err := ParseSet(c.s)
+		if c.ok && (err != nil || s.String() != c.v) {
+			t.Errorf("with %q, expected %q. got %q, %v", c.s, c.v, s, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %q, expected error. got %q", c.s, s)
+		}
+		if c.ok {
+			r, err := ParseSet(s.String())
+			if err != nil || r.String() != s.String() {
+				t.Errorf("with %q, round trip gave %q, %v", c.s, r, err)
+			}
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	a := NewSet(0o644, 0o600, 0o644)
+	b := NewSet(0o640, 0o600)
+	if a.Len() != 2 || !a.Contains(0o644) || a.Contains(0o640) || a.Contains(Perm(fs.ModeDir|0o644)) {
+		t.Errorf("unexpected membership of %v", a)
+	}
+	u := a.Union(b)
+	if u.String() != "{0600|0640|0644}" || a.Len() != 2 {
+		t.Errorf("unexpected union %v of %v and %v", u, a, b)
+	}
+	if (Set{}).Contains(0) {
+		t.Errorf("expected the zero Set to be empty")
+	}
+	var v struct{ S Set }
+	if err := json.Unmarshal([]byte(`{"S":"{0644|0640}"}`), &v); err != nil || !v.S.Contains(0o640) {
+		t.Errorf("expected set from JSON, got %v, %v", v.S, err)
+	}
+	if j, err := json.Marshal(v); err != nil || string(j) != `{"S":"{0640|0644}"}` {
+		t.Errorf("expected set as JSON, got %s, %v", j, err)
+	}
+}
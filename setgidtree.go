@@ -0,0 +1,60 @@
+package posixperm
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+)
+
+// AuditSetgidTree checks that a collaborative directory tree, such as a shared project directory, is
+// set up so that everything created in it stays in its group. It reports directories without setgid,
+// in which new files would take the primary group of whoever creates them, and, where the platform
+// reports ownership (eg with os.DirFS), entries whose group differs from that of the root of fsys.
+//
+// Symbolic links are not followed. Findings, errors, and progress are handled as for AuditHomes.
+func AuditSetgidTree(fsys fs.FS, opts ...ScanOption) ([]Finding, error) {
+	return AuditSetgidTreeContext(context.Background(), fsys, opts...)
+}
+
+// AuditSetgidTreeContext is like AuditSetgidTree, but stops promptly once ctx is done, returning the
+// findings so far along with ctx.Err().
+func AuditSetgidTreeContext(ctx context.Context, fsys fs.FS, opts ...ScanOption) ([]Finding, error) {
+	o := newScanOptions(opts)
+	var findings []Finding
+	rootGID, haveGID := -1, false
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return o.errs.add(p, err, true)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if o.xdev.crosses(d) {
+			return fs.SkipDir
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return o.errs.add(p, err, true)
+		}
+		m := Perm(fi.Mode())
+		found := len(findings)
+		flag := func(problem string) { findings = append(findings, Finding{Path: p, Mode: m, Problem: problem}) }
+		if d.IsDir() && fi.Mode()&fs.ModeSetgid == 0 {
+			flag("directory is not setgid, so new files take the primary group of their creator")
+		}
+		if _, gid, ok := fileOwner(fi); ok {
+			if p == "." {
+				rootGID, haveGID = gid, true
+			} else if haveGID && gid != rootGID {
+				flag(fmt.Sprintf("group %d differs from the group %d of the tree", gid, rootGID))
+			}
+		}
+		o.progress.visit(p, len(findings)-found)
+		return nil
+	})
+	o.progress.done()
+	return findings, o.errs.result(err)
+}
@@ -0,0 +1,55 @@
+package posixperm
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAuditSetgidTree(t *testing.T) {
+	fsys := fstest.MapFS{
+		".":              {Mode: fs.ModeDir | fs.ModeSetgid | 0o775},
+		"docs":           {Mode: fs.ModeDir | fs.ModeSetgid | 0o775},
+		"docs/a.txt":     {Mode: 0o664},
+		"docs/old":       {Mode: fs.ModeDir | 0o775},
+		"docs/old/b.txt": {Mode: 0o664},
+		"src":            {Mode: fs.ModeDir | fs.ModeSetgid | 0o775},
+	}
+	findings, err := AuditSetgidTree(fsys)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Path != "docs/old" {
+		t.Errorf("expected a finding for docs/old only, got %v", findings)
+	}
+}
+
+func TestAuditSetgidTreeGroup(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("changing the group of a file requires root")
+	}
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o664, "b": 0o664})
+	if err := os.Chmod(root, 0o775|fs.ModeSetgid); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, gid, ok := fileOwner(fi)
+	if !ok {
+		t.Skip("file ownership is not available on this platform")
+	}
+	if err := os.Chown(filepath.Join(root, "b"), -1, gid+1); err != nil {
+		t.Fatal(err)
+	}
+	findings, err := AuditSetgidTree(os.DirFS(root))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Path != "b" {
+		t.Errorf("expected a finding for b only, got %v", findings)
+	}
+}
@@ -0,0 +1,41 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// a series of "class:rights" tokens separated by spaces or commas (eg "u:rw g:rx o:-")
+var fmtClassShorthand = regexp.MustCompile(`^[ugo]:(-|[rwx]{1,3})([\s,]+[ugo]:(-|[rwx]{1,3}))*$`)
+
+// classLetter maps the "u", "g", and "o" letters to their Class.
+var classLetter = map[byte]Class{'u': ClassOwner, 'g': ClassGroup, 'o': ClassOther}
+
+// fromClassShorthand composes the rights of each class named in b into a Perm. Classes that are not
+// named get no access, and naming a class more than once is an error.
+func (p *Perm) fromClassShorthand(b []byte) error {
+	var perm Perm
+	var seen [3]bool
+	for _, tok := range strings.FieldsFunc(string(b), func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		c := classLetter[tok[0]]
+		if seen[c] {
+			return fmt.Errorf("class shorthand %q names the %v class more than once", b, c)
+		}
+		seen[c] = true
+		a, err := ParseAccess(tok[2:])
+		if err != nil {
+			return fmt.Errorf("cannot parse class shorthand %q: %w", b, err)
+		}
+		perm |= Perm(fs.FileMode(a) << c.shift())
+	}
+	*p = perm
+	return nil
+}
+
+// classShorthand renders the permission bits of p with one token per class, eg `u:rwx g:rx o:-`.
+func classShorthand(p Perm) string {
+	return fmt.Sprintf("u:%v g:%v o:%v", p.Access(ClassOwner), p.Access(ClassGroup), p.Access(ClassOther))
+}
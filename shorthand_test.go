@@ -0,0 +1,35 @@
+package posixperm
+
+import "testing"
+
+func TestClassShorthand(t *testing.T) {
+	C := []struct {
+		s  string
+		p  Perm
+		ok bool
+	}{
+		{"u:rw g:r o:r", 0o644, true},
+		{"u:rwx,g:rx,o:-", 0o750, true},
+		{"g:rx", 0o050, true},
+		{"o:- u:xr", 0o500, true},
+		{"u:rw, g:r", 0o640, true},
+		{"u:-", 0o000, true},
+		{"u:rw u:x", 0, false},
+		{"u:rr", 0, false},
+		{"a:rw", 0, false},
+		{"u:", 0, false},
+		{"u:rw,", 0, false},
+	}
+	for _, c := range C {
+		p, err := FromString(c.s)
+		if c.ok && (err != nil || p != c.p) {
+			t.Errorf("with %q, expected %v. got %v, %v", c.s, c.p, p, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %q, expected error. got %v", c.s, p)
+		}
+	}
+	if s, err := Perm(0o750).FormatAs(FormatClassShorthand); err != nil || s != "u:rwx g:rx o:-" {
+		t.Errorf("expected \"u:rwx g:rx o:-\", got %q, %v", s, err)
+	}
+}
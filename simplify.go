@@ -0,0 +1,92 @@
+package posixperm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// classLetters returns the symbolic "who" letters for the classes in set, where bit c of set
+// represents Class c. All three classes are written as "a".
+func classLetters(set uint8) string {
+	if set == 0b111 {
+		return "a"
+	}
+	var b strings.Builder
+	for c, l := range "ugo" {
+		if set&(1<<c) != 0 {
+			b.WriteRune(l)
+		}
+	}
+	return b.String()
+}
+
+// groupClauses emits one clause with operator op for each distinct nonzero Access in rights,
+// naming every class that shares it.
+func groupClauses(rights [3]Access, op string) []string {
+	var clauses []string
+	var done uint8
+	for c := range rights {
+		if rights[c] == AccessNone || done&(1<<c) != 0 {
+			continue
+		}
+		var set uint8
+		for d := c; d < len(rights); d++ {
+			if rights[d] == rights[c] {
+				set |= 1 << d
+			}
+		}
+		done |= set
+		clauses = append(clauses, classLetters(set)+op+rights[c].String())
+	}
+	return clauses
+}
+
+// symbolicMinimal returns the shortest expression in this package's symbolic syntax that evaluates
+// to the permission bits of p. Bits other than the 9 permission bits are ignored, as the syntax cannot
+// express them.
+func symbolicMinimal(p Perm) string {
+	var rights, extra, missing [3]Access
+	union, common := AccessNone, AccessAll
+	for c := ClassOwner; c <= ClassOther; c++ {
+		rights[c] = p.Access(c)
+		union |= rights[c]
+		common &= rights[c]
+	}
+	if union == AccessNone {
+		return "a-rwx"
+	}
+	for c := range rights {
+		extra[c] = rights[c] &^ common
+		missing[c] = union &^ rights[c]
+	}
+	candidates := [][]string{
+		groupClauses(rights, "="),
+		append([]string{"a=" + union.String()}, groupClauses(missing, "-")...),
+	}
+	if common != AccessNone {
+		candidates = append(candidates, append([]string{"a=" + common.String()}, groupClauses(extra, "+")...))
+	}
+	best := ""
+	for _, c := range candidates {
+		if s := strings.Join(c, " "); best == "" || len(s) < len(best) {
+			best = s
+		}
+	}
+	return best
+}
+
+// Simplify parses expr in the symbolic syntax accepted by UnmarshalText (eg `a=rwx o-r a-w o-x o+r`)
+// and returns the shortest equivalent expression (eg `a=rx o-x`), merging redundant clauses and
+// dropping no-ops. Classes with identical rights are grouped, so the result is also a stable,
+// normalized form suitable for storage. An expression granting nothing simplifies to `a-rwx`.
+func Simplify(expr string) (string, error) {
+	b := []byte(expr)
+	if !fmtSymbolicMatch.Match(b) {
+		return "", fmt.Errorf("unrecognized symbolic permission syntax %q", expr)
+	}
+	var p Perm
+	if err := p.fromSymbolic(b); err != nil {
+		return "", err
+	}
+	return symbolicMinimal(p), nil
+}
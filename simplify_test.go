@@ -0,0 +1,56 @@
+package posixperm
+
+import "testing"
+
+func TestSimplify(t *testing.T) {
+	C := []struct {
+		expr string
+		v    string
+	}{
+		{"a=rwx", "a=rwx"},
+		{"u=rwx g=rwx o=rwx", "a=rwx"},
+		{"a=rwx o-w", "a=rwx o-w"},
+		{"u+w u+r u+w", "u=rw"},
+		{"u=rw g=r o=r", "a=r u+w"},
+		{"u=x g=w o=r", "u=x g=w o=r"},
+		{"a=rwx o-r a-w o-x o+r", "a=rx o-x"},
+		{"u=rwx g=rx", "u=rwx g=rx"},
+		{"ug=rw", "ug=rw"},
+		{"u+wu=ru+wu-r", "u=w"},
+		{"a=r a-r", "a-rwx"},
+	}
+	for _, c := range C {
+		s, err := Simplify(c.expr)
+		if err != nil {
+			t.Errorf("with %q, expected %q. got error: %v", c.expr, c.v, err)
+			continue
+		}
+		if s != c.v {
+			t.Errorf("with %q, expected %q. got %q", c.expr, c.v, s)
+		}
+		want, _ := FromString(c.expr)
+		got, err := FromString(s)
+		if err != nil || got != want {
+			t.Errorf("with %q, simplified %q evaluates to %v, expected %v (%v)", c.expr, s, got, want, err)
+		}
+	}
+}
+
+func TestInvalidSimplify(t *testing.T) {
+	C := []string{"", "0644", "rwxr-xr-x", "u+q", "u=rw,g=r"}
+	for _, c := range C {
+		if s, err := Simplify(c); err == nil {
+			t.Errorf("got nil error for %q, simplified to %q", c, s)
+		}
+	}
+}
+
+func TestSymbolicMinimalExhaustive(t *testing.T) {
+	for v := Perm(0); v <= 0o777; v++ {
+		s := symbolicMinimal(v)
+		p, err := FromString(s)
+		if err != nil || p != v {
+			t.Errorf("with %v, minimal form %q evaluates to %v, %v", v, s, p, err)
+		}
+	}
+}
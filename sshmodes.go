@@ -0,0 +1,99 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// StrictModesError reports the check of OpenSSH's StrictModes, or of ssh's own private key check, that
+// a path fails. See CheckSSHKeyPath.
+type StrictModesError struct {
+	// Path is the file or directory that failed the check, which may be a parent of the key.
+	Path string
+	// Check is a short description of the failed check, eg "writable by group or other".
+	Check string
+	// Mode is the mode of Path, if it could be read.
+	Mode Perm
+}
+
+func (e *StrictModesError) Error() string {
+	return fmt.Sprintf("bad permissions for %s: %s (mode %v)", e.Path, e.Check, e.Mode)
+}
+
+// CheckSSHKeyPath checks the private key file at path the way OpenSSH does, returning a
+// *StrictModesError describing the first check that fails, or nil if both ssh and sshd with
+// StrictModes would accept it. The checks mirror two OpenSSH functions:
+//
+//   - sshkey_perm_ok, used by ssh before loading a key: a key owned by the current user must grant
+//     nothing to group or other ("UNPROTECTED PRIVATE KEY FILE"); a key owned by anyone else, such
+//     as a root-owned 0644 key, passes
+//   - safe_path, used by sshd when StrictModes is set: the key must be a regular file owned by the
+//     current user or by root and not writable by group or other, and so must every directory above
+//     it, up to and including the user's home directory if the key is inside it (or up to /
+//     otherwise)
+//
+// Symbolic links are resolved first, as ssh does. Errors reading the file system are returned as is.
+// On platforms without file ownership, every ownership check fails.
+func CheckSSHKeyPath(path string) error {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = ""
+	}
+	return checkSSHKeyPath(path, home, os.Getuid())
+}
+
+func checkSSHKeyPath(path, home string, uid int) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return err
+	}
+	if home != "" {
+		if h, err := filepath.EvalSymlinks(home); err == nil {
+			home = h
+		}
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	fail := func(p string, fi fs.FileInfo, check string) error {
+		return &StrictModesError{Path: p, Check: check, Mode: Perm(fi.Mode())}
+	}
+	if !fi.Mode().IsRegular() {
+		return fail(path, fi, "not a regular file")
+	}
+	if !ownedBySelfOrRoot(fi, uid) {
+		return fail(path, fi, "not owned by the current user or root")
+	}
+	if owner, _, ok := fileOwner(fi); ok && owner == uid && RequirePrivate(Perm(fi.Mode())) != nil {
+		return fail(path, fi, "accessible by group or other")
+	}
+	if fi.Mode()&0o022 != 0 {
+		return fail(path, fi, "writable by group or other")
+	}
+	for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+		fi, err := os.Stat(dir)
+		if err != nil {
+			return err
+		}
+		if !ownedBySelfOrRoot(fi, uid) {
+			return fail(dir, fi, "directory not owned by the current user or root")
+		}
+		if fi.Mode()&0o022 != 0 {
+			return fail(dir, fi, "directory writable by group or other")
+		}
+		if dir == home || dir == filepath.Dir(dir) {
+			return nil
+		}
+	}
+}
+
+func ownedBySelfOrRoot(fi fs.FileInfo, uid int) bool {
+	owner, _, ok := fileOwner(fi)
+	return ok && (owner == 0 || owner == uid)
+}
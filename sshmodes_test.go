@@ -0,0 +1,93 @@
+package posixperm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCheckSSHKeyPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file ownership is not available")
+	}
+	uid := os.Getuid()
+	C := []struct {
+		name  string
+		setup func(home, key string) error
+		fails string // path relative to home that fails, or "" if the key is accepted
+		root  bool   // only root can give files away
+	}{
+		{"private key", func(home, key string) error { return nil }, "", false},
+		{"group readable key", func(home, key string) error { return os.Chmod(key, 0o640) }, ".ssh/id_ed25519", false},
+		{"read-only key", func(home, key string) error { return os.Chmod(key, 0o400) }, "", false},
+		{"group writable .ssh", func(home, key string) error { return os.Chmod(filepath.Dir(key), 0o770) }, ".ssh", false},
+		{"world writable home", func(home, key string) error { return os.Chmod(home, 0o777) }, ".", false},
+		{"key is a directory", func(home, key string) error {
+			if err := os.Remove(key); err != nil {
+				return err
+			}
+			return os.Mkdir(key, 0o700)
+		}, ".ssh/id_ed25519", false},
+		{"key owned by another user", func(home, key string) error { return os.Chown(key, 4242, 4242) }, ".ssh/id_ed25519", true},
+	}
+	for _, c := range C {
+		if c.root && uid != 0 {
+			continue
+		}
+		home := t.TempDir()
+		if err := os.Chmod(home, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		key := filepath.Join(home, ".ssh", "id_ed25519")
+		if err := os.Mkdir(filepath.Dir(key), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(key, []byte("key"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.setup(home, key); err != nil {
+			t.Fatalf("with %q, got setup error: %v", c.name, err)
+		}
+		err := checkSSHKeyPath(key, home, uid)
+		var sme *StrictModesError
+		switch {
+		case c.fails == "" && err != nil:
+			t.Errorf("with %q, expected no error. got %v", c.name, err)
+		case c.fails != "" && !errors.As(err, &sme):
+			t.Errorf("with %q, expected StrictModesError. got %v", c.name, err)
+		case c.fails != "":
+			if want, _ := filepath.EvalSymlinks(filepath.Join(home, c.fails)); sme.Path != want {
+				t.Errorf("with %q, expected failure at %s. got %v", c.name, want, err)
+			}
+		}
+	}
+}
+
+func TestCheckSSHKeyPathSharedKey(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("only root can own the shared key")
+	}
+	home := t.TempDir()
+	key := filepath.Join(home, "id_ed25519")
+	if err := os.WriteFile(key, []byte("key"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(home, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSSHKeyPath(key, home, 4242); err != nil {
+		t.Errorf("expected a root owned 0644 key to be accepted for another user, got %v", err)
+	}
+	if err := checkSSHKeyPath(key, home, 0); err == nil {
+		t.Errorf("expected a 0644 key to be rejected for its owner")
+	}
+	if err := os.Chmod(key, 0o666); err != nil {
+		t.Fatal(err)
+	}
+	var sme *StrictModesError
+	if err := checkSSHKeyPath(key, home, 4242); !errors.As(err, &sme) || sme.Check != "writable by group or other" {
+		t.Errorf("expected a writable key to be rejected, got %v", err)
+	}
+}
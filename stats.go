@@ -0,0 +1,82 @@
+package posixperm
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+)
+
+// TreeStats summarizes the modes found in a file tree. See Stats.
+type TreeStats struct {
+	// Modes is a histogram of every mode found, including file type bits, so that directories and
+	// files with the same permissions are counted separately.
+	Modes map[Perm]int `json:"modes"`
+	// Entries counts every entry walked, including the root.
+	Entries int `json:"entries"`
+	// Setuid and Setgid count entries with those bits set.
+	Setuid int `json:"setuid"`
+	Setgid int `json:"setgid"`
+	// WorldWritable counts entries writable by other, excluding symbolic links, whose own permissions
+	// are meaningless.
+	WorldWritable int `json:"world_writable"`
+	// WorldWritableNoSticky counts world writable directories without the sticky bit.
+	WorldWritableNoSticky int `json:"world_writable_no_sticky"`
+	// HardLinked counts entries other than directories with more than one hard link, whose modes are
+	// shared with other paths. It is only available where the platform reports link counts.
+	HardLinked int `json:"hard_linked"`
+}
+
+// Stats walks fsys from its root and returns a histogram of the modes found along with counts of
+// notable bits. It is a cheaper alternative to a full policy scan for dashboards and inventories. By
+// default the walk stops at the first error, which is returned in a *TreeError along with the counts
+// gathered so far; see ScanErrorPolicy. Progress can be reported with ScanProgress.
+func Stats(fsys fs.FS, opts ...ScanOption) (TreeStats, error) {
+	return StatsContext(context.Background(), fsys, opts...)
+}
+
+// StatsContext is like Stats, but stops promptly once ctx is done, returning the counts gathered so
+// far along with ctx.Err().
+func StatsContext(ctx context.Context, fsys fs.FS, opts ...ScanOption) (TreeStats, error) {
+	o := newScanOptions(opts)
+	s := TreeStats{Modes: make(map[Perm]int)}
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return o.errs.add(path, err, true)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if o.xdev.crosses(d) {
+			return fs.SkipDir
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return o.errs.add(path, fmt.Errorf("cannot read mode of %s: %w", d.Name(), err), true)
+		}
+		s.add(fi.Mode())
+		if _, nlink, ok := fileLinks(fi); ok && nlink > 1 && !fi.IsDir() {
+			s.HardLinked++
+		}
+		o.progress.visit(path, 0)
+		return nil
+	})
+	o.progress.done()
+	return s, o.errs.result(err)
+}
+
+func (s *TreeStats) add(m fs.FileMode) {
+	s.Modes[Perm(m)]++
+	s.Entries++
+	if m&fs.ModeSetuid != 0 {
+		s.Setuid++
+	}
+	if m&fs.ModeSetgid != 0 {
+		s.Setgid++
+	}
+	if m&0o002 != 0 && m.Type() != fs.ModeSymlink {
+		s.WorldWritable++
+		if Perm(m).NeedsSticky() {
+			s.WorldWritableNoSticky++
+		}
+	}
+}
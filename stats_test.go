@@ -0,0 +1,62 @@
+package posixperm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+)
+
+func TestStats(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bin/tool":     {Mode: fs.ModeSetuid | 0o755},
+		"bin/helper":   {Mode: 0o755},
+		"etc/config":   {Mode: 0o644},
+		"etc/secret":   {Mode: 0o600},
+		"share/notes":  {Mode: 0o666},
+		"share/link":   {Mode: fs.ModeSymlink | 0o777},
+		"tmp":          {Mode: fs.ModeDir | fs.ModeSticky | 0o777},
+		"drop":         {Mode: fs.ModeDir | fs.ModeSetgid | 0o777},
+		"etc":          {Mode: fs.ModeDir | 0o755},
+		"bin":          {Mode: fs.ModeDir | 0o755},
+		"share":        {Mode: fs.ModeDir | 0o755},
+		"share/nested": {Mode: 0o644},
+	}
+	s, err := Stats(fsys)
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	// MapFS synthesizes the root directory
+	if s.Entries != len(fsys)+1 {
+		t.Errorf("expected %d entries, got %d", len(fsys)+1, s.Entries)
+	}
+	if s.Setuid != 1 || s.Setgid != 1 {
+		t.Errorf("expected 1 setuid and 1 setgid, got %d and %d", s.Setuid, s.Setgid)
+	}
+	if s.WorldWritable != 3 {
+		t.Errorf("expected 3 world writable, got %d", s.WorldWritable)
+	}
+	if s.WorldWritableNoSticky != 1 {
+		t.Errorf("expected 1 world writable directory without sticky, got %d", s.WorldWritableNoSticky)
+	}
+	if n := s.Modes[Perm(0o644)]; n != 2 {
+		t.Errorf("expected 2 entries with mode 0644, got %d", n)
+	}
+	if n := s.Modes[Perm(fs.ModeDir|0o755)]; n != 3 {
+		t.Errorf("expected 3 directories with mode 0755, got %d", n)
+	}
+	if _, err := json.Marshal(s); err != nil {
+		t.Errorf("got error marshaling stats: %v", err)
+	}
+}
+
+func TestStatsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s, err := StatsContext(ctx, fstest.MapFS{"a": {Mode: 0o644}})
+	if !errors.Is(err, context.Canceled) || s.Entries != 0 {
+		t.Errorf("expected context.Canceled before any entry, got %+v, %v", s, err)
+	}
+}
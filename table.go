@@ -0,0 +1,88 @@
+package posixperm
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteTable writes an aligned text table to w comparing perms, with one column per Perm and one row
+// per class, eg for perms 0644 and setuid 0755:
+//
+//	       0644  04755
+//	type   -     -
+//	owner  rw-   rws
+//	group  r--   r-x
+//	other  r--   r-x
+//
+// Each cell shows a class' rights in ls(1) style, including the setuid, setgid, and sticky bits as
+// `s` or `t` (or `S` or `T` if the underlying execute bit is not set). The type row shows the file
+// type letters of fs.FileMode's String, or `-` for a regular file. Columns are headed by labels if
+// given, such as "desired" and "actual", or by each mode in explicit octal otherwise; if given, there
+// must be exactly one label per Perm.
+func WriteTable(w io.Writer, labels []string, perms ...Perm) error {
+	if labels != nil && len(labels) != len(perms) {
+		return fmt.Errorf("table has %d labels for %d permissions", len(labels), len(perms))
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := func(name string, cell func(i int, p Perm) string) {
+		fmt.Fprint(tw, name)
+		for i, p := range perms {
+			fmt.Fprint(tw, "\t", cell(i, p))
+		}
+		fmt.Fprintln(tw)
+	}
+	row("", func(i int, p Perm) string {
+		if labels != nil {
+			return labels[i]
+		}
+		return octal(p)
+	})
+	row("type", func(_ int, p Perm) string { return fileTypeLetters(p) })
+	for c := ClassOwner; c <= ClassOther; c++ {
+		row(c.String(), func(_ int, p Perm) string { return lsTriple(p, c) })
+	}
+	return tw.Flush()
+}
+
+// fileTypeLetters returns the leading letters of p's fs.FileMode string other than the setuid,
+// setgid, and sticky letters, or `-` if there are none.
+func fileTypeLetters(p Perm) string {
+	s := fs.FileMode(p) &^ (fs.ModePerm | specialBits)
+	if s == 0 {
+		return "-"
+	}
+	return strings.TrimSuffix(s.String(), "---------")
+}
+
+// the special bit folded into the execute position of each class by ls(1), its letter, and its name
+var lsSpecial = [3]struct {
+	bit    fs.FileMode
+	letter byte
+	name   string
+}{{fs.ModeSetuid, 's', "setuid"}, {fs.ModeSetgid, 's', "setgid"}, {fs.ModeSticky, 't', "sticky"}}
+
+// lsTriple renders the rights of class c in p as ls(1) does, folding in the class' special bit.
+func lsTriple(p Perm, c Class) string {
+	b := []byte("---")
+	a := p.Access(c)
+	if a.Has(AccessRead) {
+		b[0] = 'r'
+	}
+	if a.Has(AccessWrite) {
+		b[1] = 'w'
+	}
+	if a.Has(AccessExecute) {
+		b[2] = 'x'
+	}
+	if sp := lsSpecial[c]; fs.FileMode(p)&sp.bit != 0 {
+		if b[2] == 'x' {
+			b[2] = sp.letter
+		} else {
+			b[2] = sp.letter - 'a' + 'A'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,43 @@
+package posixperm
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestWriteTable(t *testing.T) {
+	C := []struct {
+		labels []string
+		perms  []Perm
+		v      string
+	}{
+		{nil, []Perm{0o644, Perm(fs.ModeSetuid | 0o755)}, `
+       0644  04755
+type   -     -
+owner  rw-   rws
+group  r--   r-x
+other  r--   r-x
+`},
+		{[]string{"desired", "actual"}, []Perm{Perm(fs.ModeDir | fs.ModeSticky | 0o777), Perm(fs.ModeDir | fs.ModeSetgid | 0o760)}, `
+       desired  actual
+type   d        d
+owner  rwx      rwx
+group  rwx      rwS
+other  rwt      ---
+`},
+	}
+	for _, c := range C {
+		var b strings.Builder
+		if err := WriteTable(&b, c.labels, c.perms...); err != nil {
+			t.Errorf("with %v, got error: %v", c.perms, err)
+			continue
+		}
+		if want := strings.TrimPrefix(c.v, "\n"); b.String() != want {
+			t.Errorf("with %v, expected\n%s\ngot\n%s", c.perms, want, b.String())
+		}
+	}
+	if err := WriteTable(&strings.Builder{}, []string{"one"}, 0o644, 0o600); err == nil {
+		t.Errorf("expected error for mismatched labels")
+	}
+}
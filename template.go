@@ -0,0 +1,97 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+	"text/template"
+)
+
+// FuncMap returns template functions for deriving modes in text/template (and so Helm-style)
+// templates, without shelling out. Every function taking a mode accepts a Perm, an fs.FileMode, an
+// integer holding the mode's value (eg the template literal 0o644), or a string in any syntax
+// accepted by FromString:
+//
+//	perm v            -- the mode v as a Perm, which renders as eg `-rw-r--r--`
+//	permAnd a b       -- the bits set in both a and b
+//	permOr a b        -- the bits set in either a or b
+//	permWithout a b   -- the bits of a not set in b, eg `permWithout .Mode "o=w"`
+//	permAtMost a max  -- a clamped so that it grants nothing beyond max
+//	permFormat f v    -- v rendered in the named Format, eg `permFormat "explicit-octal" .Mode`
+//
+// The result can be passed to html/template by converting it to html/template.FuncMap.
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"perm": toPerm,
+		"permAnd": func(a, b any) (Perm, error) {
+			return permOp(a, b, func(x, y Perm) Perm { return x & y })
+		},
+		"permOr": func(a, b any) (Perm, error) {
+			return permOp(a, b, func(x, y Perm) Perm { return x | y })
+		},
+		"permWithout": func(a, b any) (Perm, error) {
+			return permOp(a, b, func(x, y Perm) Perm { return x &^ y })
+		},
+		"permAtMost": func(a, max any) (Perm, error) {
+			return permOp(a, max, func(x, y Perm) Perm { return x & y })
+		},
+		"permFormat": func(name string, v any) (string, error) {
+			f, err := ParseFormat(name)
+			if err != nil {
+				return "", err
+			}
+			p, err := toPerm(v)
+			if err != nil {
+				return "", err
+			}
+			return p.FormatAs(f)
+		},
+	}
+}
+
+func permOp(a, b any, op func(x, y Perm) Perm) (Perm, error) {
+	x, err := toPerm(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toPerm(b)
+	if err != nil {
+		return 0, err
+	}
+	return op(x, y), nil
+}
+
+// toPerm converts a template value to a Perm.
+func toPerm(v any) (Perm, error) {
+	switch v := v.(type) {
+	case Perm:
+		return v, nil
+	case fs.FileMode:
+		return Perm(v), nil
+	case string:
+		return FromString(v)
+	case []byte:
+		return FromString(string(v))
+	case int:
+		if v >= 0 && uint64(v) <= 1<<32-1 {
+			return Perm(v), nil
+		}
+	case int64:
+		if v >= 0 && v <= 1<<32-1 {
+			return Perm(v), nil
+		}
+	case uint32:
+		return Perm(v), nil
+	case uint64:
+		if v <= 1<<32-1 {
+			return Perm(v), nil
+		}
+	case float64:
+		// YAML and JSON numbers decode as float64
+		if v >= 0 && v <= 1<<32-1 && v == float64(uint32(v)) {
+			return Perm(v), nil
+		}
+	default:
+		return 0, fmt.Errorf("cannot use %T as a permission", v)
+	}
+	return 0, fmt.Errorf("value %v is out of range for a permission", v)
+}
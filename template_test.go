@@ -0,0 +1,55 @@
+package posixperm
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFuncMap(t *testing.T) {
+	C := []struct {
+		tmpl string
+		data any
+		v    string
+	}{
+		{`{{ perm "0644" }}`, nil, "-rw-r--r--"},
+		{`{{ permWithout .Mode "o=w" }}`, map[string]any{"Mode": "0666"}, "-rw-rw-r--"},
+		{`{{ permAnd "0755" "0644" }}`, nil, "-rw-r--r--"},
+		{`{{ permOr .Mode 0o011 }}`, map[string]any{"Mode": Perm(0o700)}, "-rwx--x--x"},
+		{`{{ permAtMost .Mode "0750" }}`, map[string]any{"Mode": float64(0o777)}, "-rwxr-x---"},
+		{`{{ permFormat "explicit-octal" (permWithout "0777" "0022") }}`, nil, "0755"},
+		{`{{ permWithout "0777" "0022" | permFormat "chmod-symbolic" }}`, nil, "u=rwx,go=rx"},
+	}
+	for _, c := range C {
+		tmpl, err := template.New("").Funcs(FuncMap()).Parse(c.tmpl)
+		if err != nil {
+			t.Errorf("with %q, got parse error: %v", c.tmpl, err)
+			continue
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, c.data); err != nil {
+			t.Errorf("with %q, got execute error: %v", c.tmpl, err)
+			continue
+		}
+		if b.String() != c.v {
+			t.Errorf("with %q, expected %q. got %q", c.tmpl, c.v, b.String())
+		}
+	}
+}
+
+func TestFuncMapErrors(t *testing.T) {
+	C := []string{
+		`{{ perm "bogus" }}`,
+		`{{ permAnd "0644" true }}`,
+		`{{ permOr "0644" -1 }}`,
+		`{{ permFormat "nonsense" "0644" }}`,
+		`{{ permFormat "basic-single" "0755" }}`,
+	}
+	for _, c := range C {
+		tmpl := template.Must(template.New("").Funcs(FuncMap()).Parse(c))
+		var b strings.Builder
+		if err := tmpl.Execute(&b, nil); err == nil {
+			t.Errorf("with %q, expected error. got %q", c, b.String())
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package posixperm
+
+import (
+	"context"
+	"time"
+)
+
+// WithRateLimit makes ApplyExpr change at most n modes per second, so that background enforcement on
+// a busy filesystem does not cause a spike of metadata writes. Paths whose modes are already right
+// are not limited, and neither is DryRun. If n is 0 or less, there is no limit.
+func WithRateLimit(n int) ApplyOption {
+	var interval time.Duration
+	if n > 0 {
+		interval = time.Second / time.Duration(n)
+	}
+	return func(o *applyOptions) { o.throttle.interval = interval }
+}
+
+// WithDirectoryPause makes ApplyExpr sleep for d before entering each directory below root, spreading
+// the metadata reads of a large walk over time.
+func WithDirectoryPause(d time.Duration) ApplyOption {
+	return func(o *applyOptions) { o.throttle.pause = d }
+}
+
+// throttle paces the changes and directory reads of a walk.
+type throttle struct {
+	interval time.Duration // the minimum time between changes
+	pause    time.Duration // the time to sleep before entering a directory
+	next     time.Time     // when the next change may be made
+}
+
+// change waits until another change may be made, or ctx is done.
+func (t *throttle) change(ctx context.Context) error {
+	if t.interval <= 0 {
+		return nil
+	}
+	now := time.Now()
+	if t.next.Before(now) {
+		t.next = now
+	}
+	err := sleepContext(ctx, t.next.Sub(now))
+	t.next = t.next.Add(t.interval)
+	return err
+}
+
+// enter waits before a directory is entered, or until ctx is done.
+func (t *throttle) enter(ctx context.Context) error {
+	return sleepContext(ctx, t.pause)
+}
+
+// sleepContext sleeps for d, returning early with ctx.Err() if ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package posixperm
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"a": 0o600, "b": 0o600, "c": 0o600, "d": 0o600})
+	start := time.Now()
+	results, err := ApplyExpr(root, "go+r", WithRateLimit(100))
+	if err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	// 5 changes, the first immediately
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected %d changes at 100 per second to take at least 40ms, took %v", len(results), elapsed)
+	}
+	start = time.Now()
+	if _, err := ApplyExpr(root, "go+r", WithRateLimit(1)); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second/2 {
+		t.Errorf("expected paths needing no change not to be limited, took %v", elapsed)
+	}
+}
+
+func TestWithDirectoryPause(t *testing.T) {
+	root := makeTree(t, map[string]fs.FileMode{"a/": 0o700, "b/": 0o700})
+	start := time.Now()
+	if _, err := ApplyExpr(root, "go+rx", WithDirectoryPause(20*time.Millisecond)); err != nil {
+		t.Fatalf("got error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected 2 pauses of 20ms, took %v", elapsed)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := ApplyExprContext(ctx, root, "go-rx", WithDirectoryPause(time.Hour)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected pause to end with the context, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package posixperm
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"strconv"
+)
+
+// a umask(1) style octal mask, with or without leading zero or "0o" prefix
+var fmtUmask = regexp.MustCompile(`^(0o)?[0-7]{1,4}$`)
+
+// Umask represents a process file mode creation mask, as set by umask(2). Only the 9 permission bits
+// are meaningful; a set bit removes that permission from newly created files and directories.
+type Umask uint32
+
+// ParseUmask parses s as an octal umask, accepting the forms used by the umask shell builtin (`22`,
+// `022`, `0022`) as well as the explicit `0o022` form. An error is returned if s is not octal or sets
+// bits outside of 0o777.
+func ParseUmask(s string) (u Umask, err error) {
+	err = u.UnmarshalText([]byte(s))
+	return
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for this type, following the rules of ParseUmask.
+func (u *Umask) UnmarshalText(b []byte) error {
+	if !fmtUmask.Match(b) {
+		return fmt.Errorf("unrecognized umask syntax %q", b)
+	}
+	s := string(b)
+	if len(s) > 2 && s[:2] == "0o" {
+		s = s[2:]
+	}
+	v, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return fmt.Errorf("cannot parse umask value %q: %w", b, err)
+	}
+	if v&^0o777 != 0 {
+		return fmt.Errorf("umask value %q sets bits outside of 0777", b)
+	}
+	*u = Umask(v)
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler for this type. It returns the String() representation.
+func (u Umask) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// String returns the conventional 4 digit octal representation of a Umask, eg `0022`.
+func (u Umask) String() string {
+	return fmt.Sprintf("%04o", uint32(u)&0o777)
+}
+
+// the bits that open(2) and mkdir(2) respectively honor in their mode argument
+const (
+	createFileBits = fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky
+	createDirBits  = fs.ModePerm | fs.ModeSticky
+)
+
+func createBits(isDir bool) fs.FileMode {
+	if isDir {
+		return createDirBits
+	}
+	return createFileBits
+}
+
+// CreateResult predicts the permissions of a file (or directory, if isDir is set) created by open(2)
+// (or mkdir(2)) with the requested mode under umask u. Only permission and special bits are returned.
+// Effects outside of the mode argument, such as a directory inheriting setgid from its parent, are not
+// modeled; see PredictChildMode.
+func CreateResult(requested Perm, u Umask, isDir bool) Perm {
+	return Perm(fs.FileMode(requested) & createBits(isDir) &^ fs.FileMode(u&0o777))
+}
+
+// ChildMode is the outcome of creating a file in a directory, as predicted by PredictChildMode.
+type ChildMode struct {
+	Mode Perm
+	// ParentGroup is set if the child is owned by the group of the directory, rather than by the
+	// primary group of the creating process.
+	ParentGroup bool
+}
+
+// PredictChildMode predicts the mode and group of a file (or directory, if isDir is set) created with
+// the requested mode under umask u in a directory with mode parent. It extends CreateResult with the
+// BSD group semantics that a setgid directory imposes on Linux: the child belongs to the directory's
+// group, and a child directory inherits setgid so that the arrangement propagates. Default POSIX ACLs,
+// which replace the umask where present, are not modeled.
+func PredictChildMode(parent, requested Perm, u Umask, isDir bool) ChildMode {
+	c := ChildMode{Mode: CreateResult(requested, u, isDir)}
+	if fs.FileMode(parent)&fs.ModeSetgid != 0 {
+		c.ParentGroup = true
+		if isDir {
+			c.Mode |= Perm(fs.ModeSetgid)
+		}
+	}
+	return c
+}
+
+// CreateRequest is the inverse of CreateResult: it returns the mode to request from open(2) (or
+// mkdir(2), if isDir is set) so that the created file lands on target under umask u. If target cannot
+// be reached at creation time because u masks some of its bits, or because the call ignores some of
+// them, an error is returned and the caller must chmod the file after creating it.
+func CreateRequest(target Perm, u Umask, isDir bool) (Perm, error) {
+	want := fs.FileMode(target) &^ fs.ModeType
+	if lost := want &^ fs.FileMode(CreateResult(target, u, isDir)); lost != 0 {
+		return 0, fmt.Errorf("mode %v cannot be created under umask %v; bits %v would be lost", want, u, lost)
+	}
+	return Perm(want), nil
+}
@@ -0,0 +1,117 @@
+package posixperm
+
+import (
+	"io/fs"
+	"testing"
+)
+
+func TestValidUmask(t *testing.T) {
+	C := []struct {
+		s string
+		v Umask
+	}{
+		{"22", 0o022},
+		{"022", 0o022},
+		{"0022", 0o022},
+		{"0o077", 0o077},
+		{"0", 0o000},
+		{"777", 0o777},
+	}
+	for _, c := range C {
+		u, err := ParseUmask(c.s)
+		if err != nil {
+			t.Errorf("with %q, expected %04o. got error: %v", c.s, c.v, err)
+		}
+		if u != c.v {
+			t.Errorf("with %q, expected %04o. got %04o", c.s, c.v, u)
+		}
+	}
+}
+
+func TestInvalidUmask(t *testing.T) {
+	C := []string{
+		"",
+		"8",
+		"1022",
+		"00022",
+		"u=rwx",
+		"0x22",
+	}
+	for _, c := range C {
+		u, err := ParseUmask(c)
+		if err == nil {
+			t.Errorf("got nil error for %q, parsed to %v", c, u)
+		}
+	}
+}
+
+func TestCreateResult(t *testing.T) {
+	C := []struct {
+		req   Perm
+		u     Umask
+		isDir bool
+		v     Perm
+	}{
+		{0o666, 0o022, false, 0o644},
+		{0o777, 0o022, true, 0o755},
+		{0o666, 0o077, false, 0o600},
+		{0o640, 0o002, false, 0o640},
+		{Perm(fs.ModeSetuid | 0o755), 0o022, false, Perm(fs.ModeSetuid | 0o755)},
+		{Perm(fs.ModeSetgid | fs.ModeSticky | 0o777), 0o022, true, Perm(fs.ModeSticky | 0o755)},
+		{Perm(fs.ModeDir | 0o777), 0o000, true, 0o777},
+	}
+	for _, c := range C {
+		if v := CreateResult(c.req, c.u, c.isDir); v != c.v {
+			t.Errorf("with %v under %v (dir %v), expected %v. got %v", c.req, c.u, c.isDir, c.v, v)
+		}
+	}
+}
+
+func TestCreateRequest(t *testing.T) {
+	C := []struct {
+		target Perm
+		u      Umask
+		isDir  bool
+		ok     bool
+	}{
+		{0o644, 0o022, false, true},
+		{0o664, 0o022, false, false},
+		{0o755, 0o022, true, true},
+		{Perm(fs.ModeSetgid | 0o755), 0o022, true, false},
+		{Perm(fs.ModeSetgid | 0o755), 0o022, false, true},
+	}
+	for _, c := range C {
+		req, err := CreateRequest(c.target, c.u, c.isDir)
+		if c.ok && err != nil {
+			t.Errorf("with %v under %v (dir %v), got error: %v", c.target, c.u, c.isDir, err)
+		}
+		if !c.ok && err == nil {
+			t.Errorf("with %v under %v (dir %v), expected error, got %v", c.target, c.u, c.isDir, req)
+		}
+		if c.ok && CreateResult(req, c.u, c.isDir) != c.target {
+			t.Errorf("with %v under %v (dir %v), request %v does not create target", c.target, c.u, c.isDir, req)
+		}
+	}
+}
+
+func TestPredictChildMode(t *testing.T) {
+	sgid := Perm(fs.ModeSetgid)
+	C := []struct {
+		parent, requested Perm
+		u                 Umask
+		isDir             bool
+		want              ChildMode
+	}{
+		{0o755, 0o666, 0o022, false, ChildMode{Mode: 0o644}},
+		{0o755, 0o777, 0o022, true, ChildMode{Mode: 0o755}},
+		{sgid | 0o775, 0o666, 0o002, false, ChildMode{Mode: 0o664, ParentGroup: true}},
+		{sgid | 0o775, 0o777, 0o002, true, ChildMode{Mode: sgid | 0o775, ParentGroup: true}},
+		{sgid | 0o770, 0o700, 0o077, true, ChildMode{Mode: sgid | 0o700, ParentGroup: true}},
+		{0o755, sgid | 0o755, 0o022, true, ChildMode{Mode: 0o755}},
+	}
+	for _, c := range C {
+		if got := PredictChildMode(c.parent, c.requested, c.u, c.isDir); got != c.want {
+			t.Errorf("with %v in %v under %v (dir %v), expected %+v. got %+v", c.requested, c.parent, c.u, c.isDir, c.want, got)
+		}
+	}
+}
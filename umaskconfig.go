@@ -0,0 +1,78 @@
+package posixperm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseLoginDefsUmask returns the UMASK setting of a login.defs(5) file read from r, and whether it
+// was present. Like the shadow utilities, the last setting wins.
+func ParseLoginDefsUmask(r io.Reader) (u Umask, found bool, err error) {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 || fields[0] != "UMASK" {
+			continue
+		}
+		if u, err = ParseUmask(fields[1]); err != nil {
+			return 0, false, fmt.Errorf("invalid login.defs UMASK: %w", err)
+		}
+		found = true
+	}
+	return u, found, sc.Err()
+}
+
+// PamUmask holds the options of a pam_umask(8) module.
+type PamUmask struct {
+	// Umask is set by the `umask=` option, if HasUmask is set. Otherwise pam_umask falls back to
+	// the UMASK of login.defs; see ParseLoginDefsUmask.
+	Umask    Umask
+	HasUmask bool
+	// UserGroups is set by the `usergroups` option; see Effective.
+	UserGroups bool
+}
+
+// ParsePamUmask parses the options of pam_umask, given either alone (`umask=0027 usergroups`) or as
+// a whole line of a pam.d file (`session optional pam_umask.so umask=0027`), in which case only what
+// follows the module path is considered. Options not affecting the umask, such as `debug`, are
+// ignored.
+func ParsePamUmask(s string) (p PamUmask, err error) {
+	fields := strings.Fields(s)
+	for i, f := range fields {
+		if strings.HasSuffix(f, "pam_umask.so") {
+			fields = fields[i+1:]
+			break
+		}
+	}
+	for _, f := range fields {
+		switch {
+		case strings.HasPrefix(f, "umask="):
+			if p.Umask, err = ParseUmask(f[len("umask="):]); err != nil {
+				return p, fmt.Errorf("invalid pam_umask option %q: %w", f, err)
+			}
+			p.HasUmask = true
+		case f == "usergroups":
+			p.UserGroups = true
+		case f == "nousergroups":
+			p.UserGroups = false
+		}
+	}
+	return p, nil
+}
+
+// Effective returns the umask pam_umask sets for a session, given the umask configured by the
+// `umask=` option or login.defs, and whether the user has a user private group: a primary group named
+// after them, as created by useradd on most distributions. With UserGroups, such a user gets group
+// bits equal to their owner bits, so that 022 becomes 002 and 077 becomes 007. pam_umask never does
+// this for root, so privateGroup should be false for root.
+func (p PamUmask) Effective(configured Umask, privateGroup bool) Umask {
+	if p.HasUmask {
+		configured = p.Umask
+	}
+	if p.UserGroups && privateGroup {
+		return configured&^0o070 | (configured&0o700)>>3
+	}
+	return configured
+}
@@ -0,0 +1,67 @@
+package posixperm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLoginDefsUmask(t *testing.T) {
+	C := []struct {
+		defs  string
+		want  Umask
+		found bool
+	}{
+		{"MAIL_DIR /var/mail\n# UMASK 077\nUMASK\t\t022\nHOME_MODE 0700\n", 0o022, true},
+		{"UMASK 022\nUMASK 027\n", 0o027, true},
+		{"MAIL_DIR /var/mail\n", 0, false},
+	}
+	for _, c := range C {
+		u, found, err := ParseLoginDefsUmask(strings.NewReader(c.defs))
+		if err != nil || u != c.want || found != c.found {
+			t.Errorf("with %q, expected %v, %v. got %v, %v, %v", c.defs, c.want, c.found, u, found, err)
+		}
+	}
+	if _, _, err := ParseLoginDefsUmask(strings.NewReader("UMASK 999\n")); err == nil {
+		t.Errorf("expected error for invalid UMASK")
+	}
+}
+
+func TestParsePamUmask(t *testing.T) {
+	C := []struct {
+		in   string
+		want PamUmask
+	}{
+		{"umask=0027", PamUmask{Umask: 0o027, HasUmask: true}},
+		{"session optional pam_umask.so umask=0022 usergroups", PamUmask{Umask: 0o022, HasUmask: true, UserGroups: true}},
+		{"session optional /lib/security/pam_umask.so debug", PamUmask{}},
+		{"usergroups nousergroups", PamUmask{}},
+	}
+	for _, c := range C {
+		if p, err := ParsePamUmask(c.in); err != nil || p != c.want {
+			t.Errorf("with %q, expected %+v. got %+v, %v", c.in, c.want, p, err)
+		}
+	}
+	if _, err := ParsePamUmask("pam_umask.so umask=0999"); err == nil {
+		t.Errorf("expected error for invalid umask option")
+	}
+}
+
+func TestPamUmaskEffective(t *testing.T) {
+	C := []struct {
+		p            PamUmask
+		configured   Umask
+		privateGroup bool
+		want         Umask
+	}{
+		{PamUmask{}, 0o022, true, 0o022},
+		{PamUmask{UserGroups: true}, 0o022, true, 0o002},
+		{PamUmask{UserGroups: true}, 0o077, true, 0o007},
+		{PamUmask{UserGroups: true}, 0o077, false, 0o077},
+		{PamUmask{Umask: 0o027, HasUmask: true}, 0o022, true, 0o027},
+	}
+	for _, c := range C {
+		if got := c.p.Effective(c.configured, c.privateGroup); got != c.want {
+			t.Errorf("with %+v and %v (private group %v), expected %v. got %v", c.p, c.configured, c.privateGroup, c.want, got)
+		}
+	}
+}
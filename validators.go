@@ -0,0 +1,58 @@
+package posixperm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// the file names run by Debian's cron from /etc/cron.d, as for run-parts(8)
+var fmtCronFragmentName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// CheckSudoersFile checks that the file at path would be accepted by sudo as a sudoers file or a
+// drop-in under /etc/sudoers.d: a regular file owned by root:root with mode 0440. Each problem found
+// is reported with the command that fixes it, and the errors are joined.
+func CheckSudoersFile(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("%s: sudoers file must be a regular file, not %v", path, fi.Mode().Type())
+	}
+	var errs []error
+	if uid, gid, ok := fileOwner(fi); !ok || uid != 0 || gid != 0 {
+		errs = append(errs, fmt.Errorf("%s: sudoers file must be owned by root:root; run `chown root:root %s`", path, path))
+	}
+	if m := fi.Mode() & chmodBits; m != 0o440 {
+		errs = append(errs, fmt.Errorf("%s: sudoers file has mode %v, expected 0440; run `chmod 0440 %s`", path, m, path))
+	}
+	return errors.Join(errs...)
+}
+
+// CheckCronFragment checks that the file at path would be run by cron from /etc/cron.d: a regular file
+// owned by root, writable by nobody else, and (as Debian's cron requires) named with only letters,
+// digits, underscores, and hyphens, so that eg `backup.sh` or `job.dpkg-old` is silently skipped. Each
+// problem found is reported with the action that fixes it, and the errors are joined.
+func CheckCronFragment(path string) error {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("%s: cron fragment must be a regular file, not %v", path, fi.Mode().Type())
+	}
+	var errs []error
+	if name := filepath.Base(path); !fmtCronFragmentName.MatchString(name) {
+		errs = append(errs, fmt.Errorf("%s: cron ignores files whose names contain characters other than letters, digits, `_`, and `-`; rename %q", path, name))
+	}
+	if uid, _, ok := fileOwner(fi); !ok || uid != 0 {
+		errs = append(errs, fmt.Errorf("%s: cron fragment must be owned by root; run `chown root %s`", path, path))
+	}
+	if m := fi.Mode() & chmodBits; m&0o022 != 0 {
+		errs = append(errs, fmt.Errorf("%s: cron fragment has mode %v, which is writable by group or other; run `chmod go-w %s`", path, m, path))
+	}
+	return errors.Join(errs...)
+}
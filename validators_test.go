@@ -0,0 +1,88 @@
+package posixperm
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckSudoersFile(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root to create root owned files")
+	}
+	dir := t.TempDir()
+	C := []struct {
+		mode fs.FileMode
+		uid  int
+		fail []string
+	}{
+		{0o440, 0, nil},
+		{0o640, 0, []string{"chmod 0440"}},
+		{0o400, 0, []string{"chmod 0440"}},
+		{0o440, 4242, []string{"chown root:root"}},
+		{0o666, 4242, []string{"chown root:root", "chmod 0440"}},
+	}
+	for _, c := range C {
+		path := filepath.Join(dir, "sudoers")
+		writeOwned(t, path, c.mode, c.uid)
+		checkErrors(t, path, CheckSudoersFile(path), c.fail)
+	}
+	if err := CheckSudoersFile(dir); err == nil {
+		t.Errorf("expected error for a directory")
+	}
+}
+
+func TestCheckCronFragment(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root to create root owned files")
+	}
+	dir := t.TempDir()
+	C := []struct {
+		name string
+		mode fs.FileMode
+		uid  int
+		fail []string
+	}{
+		{"backup", 0o644, 0, nil},
+		{"log-rotate_2", 0o600, 0, nil},
+		{"backup.sh", 0o644, 0, []string{"rename"}},
+		{"backup", 0o664, 0, []string{"chmod go-w"}},
+		{"backup", 0o644, 4242, []string{"chown root"}},
+	}
+	for _, c := range C {
+		path := filepath.Join(dir, c.name)
+		writeOwned(t, path, c.mode, c.uid)
+		checkErrors(t, path, CheckCronFragment(path), c.fail)
+	}
+}
+
+func writeOwned(t *testing.T, path string, mode fs.FileMode, uid int) {
+	t.Helper()
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(path, uid, 0); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkErrors checks that err mentions each of want, or is nil if want is empty.
+func checkErrors(t *testing.T, path string, err error, want []string) {
+	t.Helper()
+	if len(want) == 0 && err != nil {
+		t.Errorf("with %s, expected no error. got %v", path, err)
+	}
+	if len(want) > 0 && err == nil {
+		t.Errorf("with %s, expected errors %q. got nil", path, want)
+	}
+	for _, w := range want {
+		if err != nil && !strings.Contains(err.Error(), w) {
+			t.Errorf("with %s, expected error mentioning %q. got %v", path, w, err)
+		}
+	}
+}
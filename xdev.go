@@ -0,0 +1,44 @@
+package posixperm
+
+import "io/fs"
+
+// OneFileSystem makes ApplyExpr stay on the filesystem of root, like `find -xdev`, skipping
+// directories on which another filesystem is mounted, along with their contents, so that a change
+// applied to / cannot wander into network or removable mounts. It has no effect where the platform
+// does not report devices.
+func OneFileSystem() ApplyOption {
+	return func(o *applyOptions) { o.xdev = new(xdev) }
+}
+
+// ScanOneFileSystem is the equivalent of OneFileSystem for Stats and the audits. It has no effect on
+// an fs.FS that does not report devices, such as fstest.MapFS.
+func ScanOneFileSystem() ScanOption {
+	return func(o *scanOptions) { o.xdev = new(xdev) }
+}
+
+// xdev tracks the device of the root of a walk. A nil *xdev crosses nothing.
+type xdev struct {
+	dev uint64
+	set bool
+}
+
+// crosses reports whether d is a directory on a different device than the root of the walk, which
+// must be the first entry passed to it.
+func (x *xdev) crosses(d fs.DirEntry) bool {
+	if x == nil || !d.IsDir() {
+		return false
+	}
+	fi, err := d.Info()
+	if err != nil {
+		return false
+	}
+	dev, ok := fileDevice(fi)
+	if !ok {
+		return false
+	}
+	if !x.set {
+		x.dev, x.set = dev, true
+		return false
+	}
+	return dev != x.dev
+}
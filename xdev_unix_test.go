@@ -0,0 +1,52 @@
+//go:build unix
+
+package posixperm
+
+import (
+	"io/fs"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// devInfo is a FileInfo for a directory on device dev.
+type devInfo struct {
+	name string
+	dev  int
+}
+
+func (i devInfo) Name() string       { return i.name }
+func (i devInfo) Size() int64        { return 0 }
+func (i devInfo) Mode() fs.FileMode  { return fs.ModeDir | 0o755 }
+func (i devInfo) ModTime() time.Time { return time.Time{} }
+func (i devInfo) IsDir() bool        { return true }
+func (i devInfo) Sys() any {
+	st := new(syscall.Stat_t)
+	st.Dev = 1
+	if i.dev != 1 {
+		st.Dev = 2
+	}
+	return st
+}
+
+func TestXdev(t *testing.T) {
+	C := []struct {
+		dev  int
+		want bool
+	}{
+		{1, false}, // the root
+		{1, false},
+		{2, true},
+		{1, false},
+	}
+	x := new(xdev)
+	for i, c := range C {
+		if got := x.crosses(fs.FileInfoToDirEntry(devInfo{"d", c.dev})); got != c.want {
+			t.Errorf("at %d with device %d, expected %v. got %v", i, c.dev, c.want, got)
+		}
+	}
+	var none *xdev
+	if none.crosses(fs.FileInfoToDirEntry(devInfo{"d", 2})) {
+		t.Errorf("expected nil xdev to cross nothing")
+	}
+}